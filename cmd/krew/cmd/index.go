@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -23,13 +24,23 @@ import (
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/krew/cmd/krew/cmd/internal"
+	"sigs.k8s.io/krew/internal/auditlog"
 	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/policy"
 	"sigs.k8s.io/krew/pkg/constants"
 )
 
 var (
 	forceIndexDelete    *bool
+	signaturePolicy     *string
+	trustedKeys         *[]string
+	rootKeys            *[]string
+	trustOnFirstUse     *bool
+	allowedSchemes      *[]string
+	allowedHosts        *[]string
+	indexRef            *string
+	indexPriority       *int
 	errInvalidIndexName = errors.New("invalid index name")
 )
 
@@ -46,39 +57,113 @@ var indexListCmd = &cobra.Command{
 	Short: "List configured indexes",
 	Long: `Print a list of configured indexes.
 
-This command prints a list of indexes. It shows the name and the remote URL for
-each configured index in table format.`,
+This command prints a list of indexes, highest priority first. It shows the
+name, the remote URL, the ref the index is pinned to, the current commit, the
+priority and the download policy (the schemes and hosts plugins can be
+downloaded from) for each configured index in table format.`,
 	Aliases: []string{"ls"},
 	Args:    cobra.NoArgs,
 	RunE: func(_ *cobra.Command, _ []string) error {
-		indexes, err := indexoperations.ListIndexes(paths)
+		indexes, err := indexoperations.PrioritizedIndexes(paths)
 		if err != nil {
 			return errors.Wrap(err, "failed to list indexes")
 		}
 
 		var rows [][]string
 		for _, index := range indexes {
-			rows = append(rows, []string{index.Name, index.URL})
+			cfg, err := indexoperations.LoadIndexConfig(paths, index.Name)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, []string{index.Name, index.URL, cfg.Ref, shortRevision(indexCommit(index.Name)), strconv.Itoa(cfg.Priority), cfg.DownloadPolicy()})
 		}
-		return printTable(os.Stdout, []string{"INDEX", "URL"}, rows)
+		return printTable(os.Stdout, []string{"INDEX", "URL", "REF", "COMMIT", "PRIORITY", "DOWNLOAD POLICY"}, rows)
 	},
 }
 
 var indexAddCmd = &cobra.Command{
-	Use:     "add",
-	Short:   "Add a new index",
-	Long:    "Configure a new index to install plugins from.",
-	Example: "kubectl krew index add default " + constants.DefaultIndexURI,
-	Args:    cobra.ExactArgs(2),
+	Use:   "add",
+	Short: "Add a new index",
+	Long: `Configure a new index to install plugins from.
+
+The index is cloned with git, unless its URL is an HTTP URL of a .tar.gz, .tgz
+or .zip archive or of a .json catalog, which are downloaded instead, or a
+file:// URL of a local directory, whose plugin manifests are read directly.
+
+Plugin archives in an index can be signed by their publishers. With the default
+--signature-policy=warn, signatures are verified when present and a failed
+verification prints a warning. With --signature-policy=enforce, plugins without
+a valid signature by a trusted key can't be installed from the index.
+
+Signatures can name a publisher key with "keyRef" instead of embedding it. Such
+keys are trusted with the --trusted-key NAME=KEY option, where KEY is the
+base64-encoded ed25519 public key. Keys embedded in a manifest are only
+accepted by --signature-policy=enforce if they are trusted this way.
+
+Indexes can also publish metadata signed by the index maintainers, which is
+verified after every update of the index. Updates that fail verification are
+refused. To enable verification, either pass the root keys of the index with
+--root-key, or use --trust-on-first-use to trust the metadata as it is when
+the index is added. Metadata can't be verified for .json catalogs and local
+indexes.
+
+To pin the index to a git branch, tag or commit instead of the default branch
+of the remote, use the --ref option. The ref can be changed later with
+"kubectl krew index set-ref".
+
+Plugin names without an index refer to the plugin in the index with the
+highest --priority that has it. The default index wins among indexes with the
+same priority.
+
+To restrict where plugins from the index can be downloaded from, use the
+--allowed-scheme and --allowed-host options. Plugins with download URIs outside
+of these can't be installed from the index, and downloads aren't redirected
+outside of them.`,
+	Example: `  kubectl krew index add default ` + constants.DefaultIndexURI + `
+  kubectl krew index add corp https://git.corp.example/krew-index.git \
+      --allowed-scheme=https --allowed-host=artifacts.corp.example
+  kubectl krew index add dev file:///home/me/plugins
+  kubectl krew index add corp https://git.corp.example/krew-index.git --ref=v1.2.0`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(_ *cobra.Command, args []string) error {
 		name := args[0]
 		if !indexoperations.IsValidIndexName(name) {
 			return errInvalidIndexName
 		}
-		err := indexoperations.AddIndex(paths, name, args[1])
+		if len(*rootKeys) > 0 && *trustOnFirstUse {
+			return errors.New("--root-key and --trust-on-first-use are mutually exclusive")
+		}
+		cfg := indexoperations.IndexConfig{
+			SignaturePolicy: *signaturePolicy,
+			VerifyMetadata:  len(*rootKeys) > 0 || *trustOnFirstUse,
+			RootKeys:        *rootKeys,
+			AllowedSchemes:  *allowedSchemes,
+			AllowedHosts:    *allowedHosts,
+			Ref:             *indexRef,
+			Priority:        *indexPriority,
+		}
+		for _, k := range *trustedKeys {
+			keyName, key, ok := strings.Cut(k, "=")
+			if !ok || keyName == "" || key == "" {
+				return errors.Errorf("invalid --trusted-key %q, must be NAME=KEY", k)
+			}
+			if cfg.TrustedKeys == nil {
+				cfg.TrustedKeys = map[string]string{}
+			}
+			cfg.TrustedKeys[keyName] = key
+		}
+		pol, err := policy.Load()
+		if err != nil {
+			return err
+		}
+		if err := pol.CheckIndex(name, args[1]).Err(); err != nil {
+			return err
+		}
+		err = indexoperations.AddIndex(paths, name, args[1], cfg)
 		if err != nil {
 			return err
 		}
+		auditIndex(auditlog.ActionIndexAdd, name, args[1], "")
 		internal.PrintWarning(os.Stderr, `You have added a new index from %q
 The plugins in this index are not audited for security by the Krew maintainers.
 Install them at your own risk.
@@ -87,6 +172,89 @@ Install them at your own risk.
 	},
 }
 
+var indexSetRefCmd = &cobra.Command{
+	Use:   "set-ref NAME [REF]",
+	Short: "Pin an index to a git branch, tag or commit",
+	Long: `Pin a configured git index to a branch, tag or commit, and update the
+index to it. Later updates of the index follow the ref.
+
+If REF is omitted, the index tracks the default branch of its remote again.`,
+	Example: `  kubectl krew index set-ref default staging
+  kubectl krew index set-ref default`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		name := args[0]
+		if !indexoperations.IsValidIndexName(name) {
+			return errInvalidIndexName
+		}
+		var ref string
+		if len(args) > 1 {
+			ref = args[1]
+		}
+		url, err := indexoperations.RemoteURL(paths, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find index %q", name)
+		}
+		fromCommit := indexCommit(name)
+		if err := indexoperations.SetIndexRef(paths, name, ref); err != nil {
+			return err
+		}
+		if indexCommit(name) != fromCommit {
+			auditIndex(auditlog.ActionIndexUpdate, name, url, fromCommit)
+		}
+		return nil
+	},
+}
+
+var indexSetPriorityCmd = &cobra.Command{
+	Use:   "set-priority NAME PRIORITY",
+	Short: "Change the priority of an index",
+	Long: `Change the priority of a configured index.
+
+Plugin names without an index, such as "kubectl krew install foo", refer to the
+plugin in the index with the highest priority that has it. The default index
+wins among indexes with the same priority. Indexes have priority 0 unless
+configured otherwise.`,
+	Example: `  kubectl krew index set-priority corp 10
+  kubectl krew index set-priority default -1`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		name := args[0]
+		if !indexoperations.IsValidIndexName(name) {
+			return errInvalidIndexName
+		}
+		priority, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Errorf("invalid priority %q, must be an integer", args[1])
+		}
+		err = indexoperations.SetIndexPriority(paths, name, priority)
+		if os.IsNotExist(err) {
+			return errors.Errorf("index %q does not exist", name)
+		}
+		return err
+	},
+}
+
+var indexSetImplicitCmd = &cobra.Command{
+	Use:   "set-implicit NAME",
+	Short: "Make an index the implicit one for plugin names without an index",
+	Long: `Give a configured index a higher priority than all other indexes, so that
+plugin names without an index refer to the plugins in this index first.`,
+	Example: `  kubectl krew index set-implicit corp`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		name := args[0]
+		if !indexoperations.IsValidIndexName(name) {
+			return errInvalidIndexName
+		}
+		err := indexoperations.SetImplicitIndex(paths, name)
+		if os.IsNotExist(err) {
+			return errors.Errorf("index %q does not exist", name)
+		}
+		return err
+	},
+}
+
 var indexDeleteCmd = &cobra.Command{
 	Use:   "remove",
 	Short: "Remove a configured index",
@@ -107,6 +275,14 @@ func indexDelete(_ *cobra.Command, args []string) error {
 		return errInvalidIndexName
 	}
 
+	pol, err := policy.Load()
+	if err != nil {
+		return err
+	}
+	if err := pol.CheckIndexRemoval(name).Err(); err != nil {
+		return err
+	}
+
 	ps, err := installation.InstalledPluginsFromIndex(paths.InstallReceiptsPath(), name)
 	if err != nil {
 		return errors.Wrap(err, "failed querying plugins installed from the index")
@@ -125,6 +301,7 @@ Removing indexes while there are plugins installed from is not recommended
 		return errors.Errorf("there are still plugins installed from this index")
 	}
 
+	url, _ := indexoperations.RemoteURL(paths, name)
 	err = indexoperations.DeleteIndex(paths, name)
 	if os.IsNotExist(err) {
 		if *forceIndexDelete {
@@ -133,15 +310,47 @@ Removing indexes while there are plugins installed from is not recommended
 		}
 		return errors.Errorf("index %q does not exist", name)
 	}
-	return errors.Wrap(err, "error while removing the plugin index")
+	if err != nil {
+		return errors.Wrap(err, "error while removing the plugin index")
+	}
+	auditIndex(auditlog.ActionIndexRemove, name, url, "")
+	return nil
+}
+
+// shortRevision abbreviates a commit or digest of an index for display.
+func shortRevision(rev string) string {
+	rev = strings.TrimPrefix(rev, "sha256:")
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
 }
 
 func init() {
 	forceIndexDelete = indexDeleteCmd.Flags().Bool("force", false,
 		"Remove index even if it has plugins currently installed (may result in unsupported behavior)")
+	signaturePolicy = indexAddCmd.Flags().String("signature-policy", indexoperations.SignaturePolicyWarn,
+		"How to handle missing or invalid plugin signatures: warn or enforce")
+	trustedKeys = indexAddCmd.Flags().StringSlice("trusted-key", nil,
+		"Publisher key trusted for signatures in this index, as NAME=KEY (can be repeated)")
+	rootKeys = indexAddCmd.Flags().StringSlice("root-key", nil,
+		"Base64-encoded root key of the index to verify its signed metadata with (can be repeated)")
+	trustOnFirstUse = indexAddCmd.Flags().Bool("trust-on-first-use", false,
+		"Verify the signed metadata of the index, trusting its root keys when the index is added")
+	allowedSchemes = indexAddCmd.Flags().StringSlice("allowed-scheme", nil,
+		"Only allow plugin downloads with this URI scheme, such as https (can be repeated)")
+	allowedHosts = indexAddCmd.Flags().StringSlice("allowed-host", nil,
+		"Only allow plugin downloads from this host, or its subdomains with *.HOST (can be repeated)")
+	indexRef = indexAddCmd.Flags().String("ref", "",
+		"Git branch, tag or commit to pin the index to, instead of the default branch of the remote")
+	indexPriority = indexAddCmd.Flags().Int("priority", 0,
+		"Priority of the index when resolving plugin names without an index (higher wins)")
 
 	indexCmd.AddCommand(indexAddCmd)
 	indexCmd.AddCommand(indexListCmd)
 	indexCmd.AddCommand(indexDeleteCmd)
+	indexCmd.AddCommand(indexSetRefCmd)
+	indexCmd.AddCommand(indexSetPriorityCmd)
+	indexCmd.AddCommand(indexSetImplicitCmd)
 	rootCmd.AddCommand(indexCmd)
 }
@@ -25,9 +25,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
-	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/installation"
-	"sigs.k8s.io/krew/internal/pathutil"
 	"sigs.k8s.io/krew/pkg/index"
 )
 
@@ -39,15 +38,24 @@ var infoCmd = &cobra.Command{
 	Example: `  kubectl krew info PLUGIN
   kubectl krew info INDEX/PLUGIN`,
 	RunE: func(_ *cobra.Command, args []string) error {
-		index, plugin := pathutil.CanonicalPluginName(args[0])
+		index, plugin, err := resolvePluginName(args[0])
+		if err != nil {
+			return err
+		}
 
-		p, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath(index), plugin)
+		p, err := loadIndexPlugin(map[string]*indexoperations.Catalog{}, index, plugin)
 		if os.IsNotExist(err) {
 			return errors.Errorf("plugin %q not found in index %q", args[0], index)
 		} else if err != nil {
 			return errors.Wrap(err, "failed to load plugin manifest")
 		}
 		printPluginInfo(os.Stdout, index, p)
+
+		indexes, err := indexoperations.PluginIndexes(paths, p.Name)
+		if err != nil {
+			return err
+		}
+		printShadowing(os.Stdout, index, p.Name, indexes)
 		return nil
 	},
 	PreRunE: checkIndex,
@@ -60,7 +68,12 @@ func printPluginInfo(out io.Writer, indexName string, plugin index.Plugin) {
 	if platform, ok, err := installation.GetMatchingPlatform(plugin.Spec.Platforms); err == nil && ok {
 		if platform.URI != "" {
 			fmt.Fprintf(out, "URI: %s\n", platform.URI)
-			fmt.Fprintf(out, "SHA256: %s\n", platform.Sha256)
+			if platform.Sha256 != "" {
+				fmt.Fprintf(out, "SHA256: %s\n", platform.Sha256)
+			}
+			if platform.Sha512 != "" {
+				fmt.Fprintf(out, "SHA512: %s\n", platform.Sha512)
+			}
 		}
 	}
 	if plugin.Spec.Version != "" {
@@ -77,6 +90,26 @@ func printPluginInfo(out io.Writer, indexName string, plugin index.Plugin) {
 	}
 }
 
+// printShadowing prints which plugins with the same name in other indexes
+// the plugin shadows, or which plugin shadows it. The indexes that have a
+// plugin with the name are ordered by priority.
+func printShadowing(out io.Writer, indexName, name string, indexes []string) {
+	if len(indexes) == 0 {
+		return
+	}
+	if indexes[0] != indexName {
+		fmt.Fprintf(out, "SHADOWED BY: %s/%s (%q refers to it)\n", indexes[0], name, name)
+		return
+	}
+	var shadowed []string
+	for _, idx := range indexes[1:] {
+		shadowed = append(shadowed, idx+"/"+name)
+	}
+	if len(shadowed) > 0 {
+		fmt.Fprintf(out, "SHADOWS: %s\n", strings.Join(shadowed, ", "))
+	}
+}
+
 // indent converts strings to an indented format ready for printing.
 // Example:
 //
@@ -19,17 +19,19 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/krew/cmd/krew/cmd/internal"
+	"sigs.k8s.io/krew/internal/auditlog"
 	"sigs.k8s.io/krew/internal/download"
 	"sigs.k8s.io/krew/internal/index/indexscanner"
 	"sigs.k8s.io/krew/internal/index/validation"
 	"sigs.k8s.io/krew/internal/installation"
-	"sigs.k8s.io/krew/internal/pathutil"
+	"sigs.k8s.io/krew/internal/policy"
 	"sigs.k8s.io/krew/pkg/constants"
 	"sigs.k8s.io/krew/pkg/index"
 )
@@ -113,7 +115,10 @@ Remarks:
 
 			var install []pluginEntry
 			for _, name := range pluginNames {
-				indexName, pluginName := pathutil.CanonicalPluginName(name)
+				indexName, pluginName, err := resolvePluginName(name)
+				if err != nil {
+					return err
+				}
 				if !validation.IsSafePluginName(pluginName) {
 					return unsafePluginNameErr(pluginName)
 				}
@@ -155,6 +160,25 @@ Remarks:
 				return cmd.Help()
 			}
 
+			pol, err := policy.Load()
+			if err != nil {
+				return err
+			}
+			var devFlags []string
+			for flag, value := range map[string]string{"--manifest": *manifest, "--manifest-url": *manifestURL, "--archive": *archiveFileOverride} {
+				if value != "" {
+					devFlags = append(devFlags, flag)
+				}
+			}
+			sort.Strings(devFlags)
+			if err := pol.CheckDevelopmentFlags(devFlags...).Err(); err != nil {
+				return err
+			}
+			indexes, err := indexURLs()
+			if err != nil {
+				return err
+			}
+
 			for _, pluginEntry := range install {
 				klog.V(2).Infof("Will install plugin: %s/%s\n", pluginEntry.indexName, pluginEntry.p.Name)
 			}
@@ -164,6 +188,14 @@ Remarks:
 			for _, entry := range install {
 				plugin := entry.p
 				fmt.Fprintf(os.Stderr, "Installing plugin: %s\n", plugin.Name)
+				if err := checkPluginPolicy(pol, indexes, entry.indexName, plugin).Err(); err != nil {
+					klog.Warningf("failed to install plugin %q: %v", plugin.Name, err)
+					if returnErr == nil {
+						returnErr = err
+					}
+					failed = append(failed, plugin.Name)
+					continue
+				}
 				err = installation.Install(paths, plugin, entry.indexName, installation.InstallOpts{
 					ArchiveFileOverride: *archiveFileOverride,
 					EnableNetrc:         *enableNetrc,
@@ -182,6 +214,7 @@ Remarks:
 					continue
 				}
 				fmt.Fprintf(os.Stderr, "Installed plugin: %s\n", plugin.Name)
+				auditInstalledPlugin(auditlog.ActionInstall, plugin.Name, "")
 				output := fmt.Sprintf("Use this plugin:\n\tkubectl %s\n", plugin.Name)
 				if plugin.Spec.Homepage != "" {
 					output += fmt.Sprintf("Documentation:\n\t%s\n", plugin.Spec.Homepage)
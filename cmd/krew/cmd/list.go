@@ -25,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/installation"
 )
 
@@ -38,7 +39,10 @@ func init() {
 Remarks:
   Redirecting the output of this command to a program or file will only print
   the names of the plugins installed. This output can be piped back to the
-  "install" command.`,
+  "install" command.
+
+  Installed plugins that were removed from, renamed in or deprecated by their
+  index are reported as a warning.`,
 		Aliases: []string{"ls"},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			receipts, err := installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
@@ -54,16 +58,19 @@ Remarks:
 				}
 				sort.Strings(names)
 				fmt.Fprintln(os.Stdout, strings.Join(names, "\n"))
-				return nil
-			}
-
-			// print table
-			var rows [][]string
-			for _, r := range receipts {
-				rows = append(rows, []string{displayName(r.Plugin, indexOf(r)), r.Spec.Version})
+			} else {
+				// print table
+				var rows [][]string
+				for _, r := range receipts {
+					rows = append(rows, []string{displayName(r.Plugin, indexOf(r)), r.Spec.Version})
+				}
+				rows = sortByFirstColumn(rows)
+				if err := printTable(os.Stdout, []string{"PLUGIN", "VERSION"}, rows); err != nil {
+					return err
+				}
 			}
-			rows = sortByFirstColumn(rows)
-			return printTable(os.Stdout, []string{"PLUGIN", "VERSION"}, rows)
+			printOrphanedPlugins(os.Stderr, findOrphanedPlugins(receipts, make(map[string]*indexoperations.Catalog)))
+			return nil
 		},
 		PreRunE: checkIndex,
 	}
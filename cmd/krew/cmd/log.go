@@ -0,0 +1,175 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/auditlog"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/installation/receipt"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func init() {
+	var filter auditlog.Filter
+	var since, output *string
+
+	// logCmd represents the log command
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the audit log of plugin and index operations",
+		Long: `Show the audit log of plugin and index operations.
+
+Krew records every install, upgrade, reinstall and uninstall of a plugin and
+every added, removed and updated index in an append-only log, with the plugin
+version, the download URI and checksum, the index commit, the user and the
+time.`,
+		Example: `  kubectl krew log
+  kubectl krew log --plugin=ctx --since=720h
+  kubectl krew log --action=install --since=2026-01-01 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if *since != "" {
+				t, err := parseSince(*since)
+				if err != nil {
+					return err
+				}
+				filter.Since = t
+			}
+			events, err := auditlog.Read(paths.AuditLogPath(), filter)
+			if err != nil {
+				return err
+			}
+
+			switch *output {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				for _, e := range events {
+					if err := enc.Encode(e); err != nil {
+						return errors.Wrap(err, "failed to print event")
+					}
+				}
+				return nil
+			case "", "table":
+				var rows [][]string
+				for _, e := range events {
+					version := e.Version
+					if e.FromVersion != "" {
+						version = e.FromVersion + " -> " + e.Version
+					}
+					rows = append(rows, []string{e.Time.Local().Format(time.RFC3339), e.Action, e.Plugin, version, e.Index, e.User})
+				}
+				return printTable(os.Stdout, []string{"TIME", "ACTION", "PLUGIN", "VERSION", "INDEX", "USER"}, rows)
+			default:
+				return errors.Errorf("invalid output format %q, must be table or json", *output)
+			}
+		},
+	}
+
+	logCmd.Flags().StringVar(&filter.Action, "action", "", "Only show events of this action (install, upgrade, reinstall, uninstall, index-add, index-remove, index-update)")
+	logCmd.Flags().StringVar(&filter.Plugin, "plugin", "", "Only show events of this plugin")
+	logCmd.Flags().StringVar(&filter.Index, "index", "", "Only show events of this index")
+	since = logCmd.Flags().String("since", "", "Only show events since a date (2006-01-02 or RFC3339) or for a duration (such as 24h)")
+	output = logCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
+	rootCmd.AddCommand(logCmd)
+}
+
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	return t, errors.Wrapf(err, "invalid --since value %q", s)
+}
+
+// auditInstalledPlugin records an operation on an installed plugin in the
+// audit log, with the details from its receipt.
+func auditInstalledPlugin(action, name, fromVersion string) {
+	r, err := receipt.Load(paths.PluginInstallReceiptPath(name))
+	if err != nil {
+		klog.Warningf("failed to record %s of plugin %q in audit log: %v", action, name, err)
+		return
+	}
+	auditPlugin(action, r, fromVersion)
+}
+
+// auditPlugin records an operation on the plugin of the receipt in the audit
+// log.
+func auditPlugin(action string, r index.Receipt, fromVersion string) {
+	e := auditlog.Event{
+		Action:      action,
+		Plugin:      r.Name,
+		Version:     r.Spec.Version,
+		FromVersion: fromVersion,
+		URI:         r.Status.URI,
+		Index:       indexOf(r),
+	}
+	if strings.HasPrefix(r.Status.Digest, "sha256:") {
+		e.Sha256 = strings.TrimPrefix(r.Status.Digest, "sha256:")
+	} else if platform, ok, err := installation.GetMatchingPlatform(r.Spec.Platforms); err == nil && ok {
+		e.Sha256 = platform.Sha256
+	}
+	e.IndexCommit = r.Status.Source.Commit
+	if e.IndexCommit == "" && action != auditlog.ActionUninstall {
+		e.IndexCommit = indexCommit(e.Index)
+	}
+	appendAuditEvent(e)
+}
+
+// auditIndex records an operation on an index in the audit log.
+func auditIndex(action, name, url, fromCommit string) {
+	e := auditlog.Event{
+		Action:          action,
+		Index:           name,
+		IndexURL:        url,
+		FromIndexCommit: fromCommit,
+	}
+	if action != auditlog.ActionIndexRemove {
+		e.IndexCommit = indexCommit(name)
+	}
+	appendAuditEvent(e)
+}
+
+func appendAuditEvent(e auditlog.Event) {
+	if err := auditlog.Append(paths.AuditLogPath(), e); err != nil {
+		klog.Warningf("failed to record %s in audit log: %v", e.Action, err)
+	}
+}
+
+// indexCommit returns the commit the local copy of the named index is at, or
+// an empty string if it can't be determined.
+func indexCommit(name string) string {
+	if name == "detached" {
+		return ""
+	}
+	commit, err := indexoperations.Revision(paths, name)
+	if err != nil {
+		klog.V(2).Infof("failed to get commit of index %q: %v", name, err)
+		return ""
+	}
+	return commit
+}
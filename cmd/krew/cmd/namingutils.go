@@ -16,7 +16,12 @@ package cmd
 
 import (
 	"regexp"
+	"strings"
 
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/pathutil"
 	"sigs.k8s.io/krew/pkg/constants"
 	"sigs.k8s.io/krew/pkg/index"
 )
@@ -56,3 +61,22 @@ func canonicalName(p index.Plugin, indexName string) string {
 func isCanonicalName(s string) bool {
 	return canonicalNameRegex.MatchString(s)
 }
+
+// resolvePluginName returns the index and name of the plugin that a NAME or
+// INDEX/NAME argument refers to. A NAME without an index refers to the plugin
+// in the highest-priority index that has it, or to the default index if no
+// index has it.
+func resolvePluginName(in string) (string, string, error) {
+	if strings.Contains(in, "/") {
+		indexName, name := pathutil.CanonicalPluginName(in)
+		return indexName, name, nil
+	}
+	indexes, err := indexoperations.PluginIndexes(paths, in)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to resolve index of plugin %q", in)
+	}
+	if len(indexes) == 0 {
+		return constants.DefaultIndexName, in, nil
+	}
+	return indexes[0], in, nil
+}
@@ -24,7 +24,7 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
-	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/installation"
 	"sigs.k8s.io/krew/internal/installation/semver"
 )
@@ -37,7 +37,8 @@ func init() {
 in the local index. This command does not perform any upgrades.
 
 Use "kubectl krew update" to refresh the index before checking for
-outdated plugins.
+outdated plugins. Installed plugins that were removed from, renamed in or
+deprecated by their index are reported as a warning.
 
 To upgrade all outdated plugins, use:
   kubectl krew upgrade`,
@@ -48,6 +49,7 @@ To upgrade all outdated plugins, use:
 			}
 
 			var rows [][]string
+			catalogs := make(map[string]*indexoperations.Catalog)
 			for _, r := range receipts {
 				indexName := indexOf(r)
 				pluginName := r.Name
@@ -59,10 +61,10 @@ To upgrade all outdated plugins, use:
 				}
 
 				// Load latest version from the index
-				indexPlugin, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath(indexName), pluginName)
+				indexPlugin, err := loadIndexPlugin(catalogs, indexName, pluginName)
 				if err != nil {
 					if os.IsNotExist(err) {
-						klog.V(1).Infof("Skipping %q: plugin no longer exists in index %q", pluginName, indexName)
+						// Reported with the orphaned plugins below.
 						continue
 					}
 					return errors.Wrapf(err, "failed to load index entry for plugin %q", pluginName)
@@ -87,6 +89,8 @@ To upgrade all outdated plugins, use:
 				}
 			}
 
+			defer printOrphanedPlugins(os.Stderr, findOrphanedPlugins(receipts, catalogs))
+
 			if len(rows) == 0 {
 				fmt.Fprintln(os.Stderr, "All plugins are up to date.")
 				return nil
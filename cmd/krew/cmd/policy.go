@@ -0,0 +1,169 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/internal/index/validation"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/policy"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the organization policy",
+	Long: `Inspect the organization policy that restricts which plugins and indexes
+can be used.
+
+The policy is read from ` + policy.SystemPath() + ` and from the file
+that the KREW_POLICY environment variable points to. Operations must be allowed
+by both files.`,
+	Args: cobra.NoArgs,
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check [PLUGIN...]",
+	Short: "Explain policy decisions",
+	Long: `Check configured indexes and installed plugins against the policy and explain
+each decision.
+
+If plugin names are given, check installing these plugins from the local copy of
+their index instead.`,
+	Example: `  kubectl krew policy check
+  kubectl krew policy check ctx corp/foo`,
+	RunE: func(_ *cobra.Command, args []string) error {
+		pol, err := policy.Load()
+		if err != nil {
+			return err
+		}
+		if len(pol.Sources()) == 0 {
+			fmt.Fprintln(os.Stderr, "No policy files found, all operations are allowed.")
+		}
+		for _, source := range pol.Sources() {
+			fmt.Fprintf(os.Stderr, "Using policy file: %s\n", source)
+		}
+
+		indexes, err := indexURLs()
+		if err != nil {
+			return err
+		}
+
+		var rows [][]string
+		var denied int
+		addRow := func(kind, name string, d policy.Decision) {
+			decision := "allowed"
+			if !d.Allowed {
+				decision = "denied"
+				denied++
+			}
+			source := d.Source
+			if source == "" {
+				source = "-"
+			}
+			rows = append(rows, []string{kind, name, decision, source, d.Reason})
+		}
+
+		if len(args) > 0 {
+			for _, arg := range args {
+				indexName, pluginName, err := resolvePluginName(arg)
+				if err != nil {
+					return err
+				}
+				if !validation.IsSafePluginName(pluginName) {
+					return unsafePluginNameErr(pluginName)
+				}
+				plugin, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath(indexName), pluginName)
+				if err != nil {
+					if os.IsNotExist(err) {
+						return errors.Errorf("plugin %q does not exist in the plugin index", arg)
+					}
+					return errors.Wrapf(err, "failed to load plugin %q from the index", arg)
+				}
+				addRow("plugin", displayName(plugin, indexName), checkPluginPolicy(pol, indexes, indexName, plugin))
+			}
+		} else {
+			for name, url := range indexes {
+				addRow("index", name, pol.CheckIndex(name, url))
+			}
+			for _, r := range pol.RequiredIndexes() {
+				if _, ok := indexes[r.Name]; !ok {
+					addRow("index", r.Name, policy.Decision{Reason: fmt.Sprintf("required index with url %q is not configured", r.URL)})
+				}
+			}
+			receipts, err := installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
+			if err != nil {
+				return errors.Wrap(err, "failed to find all installed versions")
+			}
+			for _, r := range receipts {
+				addRow("plugin", displayName(r.Plugin, indexOf(r)), checkPluginPolicy(pol, indexes, indexOf(r), r.Plugin))
+			}
+		}
+
+		sort.SliceStable(rows, func(a, b int) bool {
+			if rows[a][0] != rows[b][0] {
+				return rows[a][0] < rows[b][0]
+			}
+			return rows[a][1] < rows[b][1]
+		})
+		if err := printTable(os.Stdout, []string{"KIND", "NAME", "DECISION", "POLICY", "REASON"}, rows); err != nil {
+			return err
+		}
+		if denied > 0 {
+			return errors.Errorf("%d policy violation(s) found", denied)
+		}
+		return nil
+	},
+	PreRunE: checkIndex,
+}
+
+// checkPluginPolicy decides if the plugin can be installed from the named
+// index, checking the index URL for plugins that aren't installed from a
+// manifest file.
+func checkPluginPolicy(pol policy.Set, indexes map[string]string, indexName string, p index.Plugin) policy.Decision {
+	if indexName != "detached" {
+		if d := pol.CheckIndex(indexName, indexes[indexName]); !d.Allowed {
+			return d
+		}
+	}
+	return pol.CheckPlugin(indexName, p.Name, p.Spec.Version)
+}
+
+// indexURLs returns the URLs of the configured indexes by their name.
+func indexURLs() (map[string]string, error) {
+	indexes, err := indexoperations.ListIndexes(paths)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list indexes")
+	}
+	urls := make(map[string]string, len(indexes))
+	for _, idx := range indexes {
+		urls[idx.Name] = idx.URL
+	}
+	return urls, nil
+}
+
+func init() {
+	policyCmd.AddCommand(policyCheckCmd)
+	rootCmd.AddCommand(policyCmd)
+}
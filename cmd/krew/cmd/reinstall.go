@@ -0,0 +1,167 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/auditlog"
+	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/internal/index/validation"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/installation/receipt"
+	"sigs.k8s.io/krew/internal/policy"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func init() {
+	var all, fromIndex *bool
+	var enableNetrc *bool
+	var netrcFile *string
+
+	// Resolve default netrc file path
+	defaultNetrcFile, err := resolveNetrcFile("")
+	if err != nil {
+		// If we can't resolve home directory, fall back to empty string
+		// The error will be handled later when netrc is actually used
+		defaultNetrcFile = ""
+	}
+
+	// reinstallCmd represents the reinstall command
+	reinstallCmd := &cobra.Command{
+		Use:   "reinstall",
+		Short: "Reinstall plugins from their receipts",
+		Long: `Download and install plugins again, for example to repair plugins whose files
+were removed or modified.
+
+By default, the plugin manifest stored in the receipt of each plugin is
+installed again, so that the same version is installed from the same location.
+With --from-index, the version in the local copy of the plugin index is
+installed instead.
+
+Examples:
+  To reinstall one or multiple plugins, run:
+    kubectl krew reinstall NAME [NAME...]
+
+  To reinstall all installed plugins, run:
+    kubectl krew reinstall --all
+
+Remarks:
+  Failure to reinstall a plugin will not stop the reinstallation of other plugins.`,
+		Aliases: []string{"repair"},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if *all == (len(args) > 0) {
+				return errors.New("must specify either plugin names or --all; not both")
+			}
+
+			var receipts []index.Receipt
+			if *all {
+				var err error
+				receipts, err = installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
+				if err != nil {
+					return errors.Wrap(err, "failed to find all installed versions")
+				}
+			}
+			for _, name := range args {
+				if isCanonicalName(name) {
+					return errors.New("reinstall command does not support INDEX/PLUGIN syntax; just specify PLUGIN")
+				} else if !validation.IsSafePluginName(name) {
+					return unsafePluginNameErr(name)
+				}
+				r, err := receipt.Load(paths.PluginInstallReceiptPath(name))
+				if os.IsNotExist(err) {
+					return errors.Errorf("plugin %q is not installed", name)
+				} else if err != nil {
+					return errors.Wrapf(err, "failed to load receipt of plugin %q", name)
+				}
+				receipts = append(receipts, r)
+			}
+
+			pol, err := policy.Load()
+			if err != nil {
+				return err
+			}
+			indexes, err := indexURLs()
+			if err != nil {
+				return err
+			}
+
+			var failed []string
+			var returnErr error
+			for _, r := range receipts {
+				indexName := indexOf(r)
+				pluginDisplayName := displayName(r.Plugin, indexName)
+				fmt.Fprintf(os.Stderr, "Reinstalling plugin: %s\n", pluginDisplayName)
+				err := reinstall(r, indexName, *fromIndex, pol, indexes, installation.InstallOpts{
+					EnableNetrc: *enableNetrc,
+					NetrcFile:   *netrcFile,
+				})
+				if err != nil {
+					klog.Warningf("failed to reinstall plugin %q: %v", pluginDisplayName, err)
+					if returnErr == nil {
+						returnErr = err
+					}
+					failed = append(failed, pluginDisplayName)
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "Reinstalled plugin: %s\n", pluginDisplayName)
+				auditInstalledPlugin(auditlog.ActionReinstall, r.Name, r.Spec.Version)
+			}
+			if len(failed) > 0 {
+				return errors.Wrapf(returnErr, "failed to reinstall some plugins: %+v", failed)
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !*fromIndex {
+				return checkIndex(cmd, args)
+			}
+			return ensureIndexes(cmd, args)
+		},
+	}
+
+	all = reinstallCmd.Flags().Bool("all", false, "Reinstall all installed plugins")
+	fromIndex = reinstallCmd.Flags().Bool("from-index", false, "Install the version in the plugin index instead of the one in the receipt")
+	enableNetrc = reinstallCmd.Flags().Bool("enable-netrc", false, "read .netrc file for login credentials, used for downloading plugin packages")
+	netrcFile = reinstallCmd.Flags().String("netrc-file", defaultNetrcFile, "path to .netrc file for authentication (defaults to ~/.netrc or %HOME%/_netrc on Windows)")
+	rootCmd.AddCommand(reinstallCmd)
+}
+
+// reinstall installs the plugin manifest from the receipt, or with fromIndex
+// the one from its index, again if the policy allows it.
+func reinstall(r index.Receipt, indexName string, fromIndex bool, pol policy.Set, indexes map[string]string, opts installation.InstallOpts) error {
+	plugin := r.Plugin
+	if fromIndex {
+		if indexName == "detached" {
+			return errors.New("plugin was installed via manifest and has no index to install from")
+		}
+		var err error
+		plugin, err = indexscanner.LoadPluginByName(paths.IndexPluginsPath(indexName), r.Name)
+		if os.IsNotExist(err) {
+			return errors.Errorf("plugin does not exist in the plugin index %q", indexName)
+		} else if err != nil {
+			return errors.Wrap(err, "failed to load the plugin manifest from the index")
+		}
+	}
+	if err := checkPluginPolicy(pol, indexes, indexName, plugin).Err(); err != nil {
+		return err
+	}
+	return installation.Reinstall(paths, plugin, indexName, opts)
+}
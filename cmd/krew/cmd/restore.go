@@ -0,0 +1,170 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/auditlog"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/policy"
+	"sigs.k8s.io/krew/pkg/constants"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func init() {
+	var enableNetrc *bool
+	var netrcFile *string
+
+	// Resolve default netrc file path
+	defaultNetrcFile, err := resolveNetrcFile("")
+	if err != nil {
+		// If we can't resolve home directory, fall back to empty string
+		// The error will be handled later when netrc is actually used
+		defaultNetrcFile = ""
+	}
+
+	// restoreCmd represents the restore command
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore plugins from the receipts directory",
+		Long: `Restore all plugins that have a receipt, for example after copying the
+receipts directory of krew to a new machine.
+
+This command adds the default index if plugins were installed from it and it
+is not configured, and installs each plugin from the manifest stored in its
+receipt. Plugins that are installed and pass verification are skipped.
+
+Remarks:
+  Other indexes are not added, as receipts don't record their settings, such
+  as signature policies, trusted keys and download restrictions, and their
+  plugins are skipped. The command prints how to add them; add them with their
+  original options and run the command again to restore their plugins.
+  Failure to restore a plugin will not stop the restoration of other plugins.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			receipts, err := installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
+			if err != nil {
+				return errors.Wrap(err, "failed to find all installed versions")
+			}
+			pol, err := policy.Load()
+			if err != nil {
+				return err
+			}
+			if err := restoreIndexes(receipts, pol); err != nil {
+				return err
+			}
+			indexes, err := indexURLs()
+			if err != nil {
+				return err
+			}
+
+			rows, failed := restorePlugins(receipts, pol, indexes, installation.InstallOpts{
+				EnableNetrc: *enableNetrc,
+				NetrcFile:   *netrcFile,
+			})
+			if err := printTable(os.Stdout, []string{"PLUGIN", "VERSION", "RESULT", "DETAILS"}, sortByFirstColumn(rows)); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return errors.Errorf("failed to restore %d plugin(s)", failed)
+			}
+			return nil
+		},
+	}
+
+	enableNetrc = restoreCmd.Flags().Bool("enable-netrc", false, "read .netrc file for login credentials, used for downloading plugin packages")
+	netrcFile = restoreCmd.Flags().String("netrc-file", defaultNetrcFile, "path to .netrc file for authentication (defaults to ~/.netrc or %HOME%/_netrc on Windows)")
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// restorePlugins reinstalls the plugins of the receipts that are not
+// installed or fail verification, and returns a row for each plugin with the
+// result and the number of plugins that failed to restore. Plugins of indexes
+// that are not configured are skipped, as they would be installed without the
+// settings of their index.
+func restorePlugins(receipts []index.Receipt, pol policy.Set, indexes map[string]string, opts installation.InstallOpts) ([][]string, int) {
+	var rows [][]string
+	var failed int
+	for _, r := range receipts {
+		indexName := indexOf(r)
+		pluginDisplayName := displayName(r.Plugin, indexName)
+		if problems, err := installation.VerifyInstallation(paths, r); err == nil && len(problems) == 0 {
+			rows = append(rows, []string{pluginDisplayName, r.Spec.Version, "skipped", "already installed"})
+			continue
+		}
+		if _, ok := indexes[indexName]; !ok && indexName != "detached" {
+			rows = append(rows, []string{pluginDisplayName, r.Spec.Version, "skipped", fmt.Sprintf("index %q not configured", indexName)})
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "Restoring plugin: %s\n", pluginDisplayName)
+		if err := reinstall(r, indexName, false, pol, indexes, opts); err != nil {
+			klog.V(1).Infof("failed to restore plugin %q: %+v", pluginDisplayName, err)
+			rows = append(rows, []string{pluginDisplayName, r.Spec.Version, "failed", err.Error()})
+			failed++
+			continue
+		}
+		rows = append(rows, []string{pluginDisplayName, r.Spec.Version, "restored", ""})
+		auditInstalledPlugin(auditlog.ActionReinstall, r.Name, r.Spec.Version)
+	}
+	return rows, failed
+}
+
+// restoreIndexes adds the default index if plugins in the receipts were
+// installed from it and it is not configured. For other indexes that are not
+// configured, it prints how to add them, as their settings are unknown.
+func restoreIndexes(receipts []index.Receipt, pol policy.Set) error {
+	configured, err := indexURLs()
+	if err != nil {
+		return err
+	}
+	for _, r := range receipts {
+		name, url := indexOf(r), r.Status.Source.URL
+		if _, ok := configured[name]; ok || name == "detached" {
+			continue
+		}
+		if url == "" && name == constants.DefaultIndexName {
+			url = index.DefaultIndex()
+		}
+		configured[name] = url
+		if url == "" {
+			fmt.Fprintf(os.Stderr, "WARNING: Cannot add index %q for plugin %q, its URL is unknown.\n", name, r.Name)
+			continue
+		}
+		if name != constants.DefaultIndexName || url != index.DefaultIndex() {
+			fmt.Fprintf(os.Stderr, "WARNING: Index %q of plugin %q is not configured. Add it with the options it was added with before, like:\n"+
+				"  kubectl krew index add %s %s\n", name, r.Name, name, url)
+			continue
+		}
+		if err := pol.CheckIndex(name, url).Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: Cannot add index %q: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Adding plugin index %q from %s.\n", name, url)
+		if err := indexoperations.AddIndex(paths, name, url, indexoperations.IndexConfig{}); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: Failed to add index %q: %v\n", name, err)
+			continue
+		}
+		auditIndex(auditlog.ActionIndexAdd, name, url, "")
+	}
+	return nil
+}
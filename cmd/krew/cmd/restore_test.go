@@ -0,0 +1,50 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/policy"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func Test_restorePlugins_skipsUnconfiguredIndexes(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	defer func(p environment.Paths) { paths = p }(paths)
+	paths = environment.NewPaths(tmpDir.Root())
+
+	r := testutil.NewReceipt().WithPlugin(testutil.NewPlugin().WithName("foo").WithVersion("v1.0.0").
+		WithPlatforms(testutil.NewPlatform().V()).V()).
+		WithStatus(index.ReceiptStatus{Source: index.SourceIndex{Name: "corp", URL: "https://git.corp.example/krew-index.git"}}).V()
+
+	rows, failed := restorePlugins([]index.Receipt{r}, policy.Set{}, map[string]string{}, installation.InstallOpts{})
+	expected := [][]string{{"corp/foo", "v1.0.0", "skipped", `index "corp" not configured`}}
+	if diff := cmp.Diff(expected, rows); diff != "" {
+		t.Errorf("restorePlugins() rows mismatch (-want +got):\n%s", diff)
+	}
+	if failed != 0 {
+		t.Errorf("restorePlugins() failed = %d, expected 0", failed)
+	}
+	if _, err := os.Stat(paths.PluginInstallPath("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected plugin of unconfigured index not to be installed, got err=%v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/cmd/krew/cmd/internal"
+	"sigs.k8s.io/krew/internal/auditlog"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/installation/receipt"
+	"sigs.k8s.io/krew/internal/policy"
+	"sigs.k8s.io/krew/pkg/constants"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// Statuses of orphaned plugins.
+const (
+	orphanRemoved    = "removed"
+	orphanRenamed    = "renamed"
+	orphanDeprecated = "deprecated"
+)
+
+// orphanedPlugin is an installed plugin that was removed from, renamed in or
+// deprecated by its index.
+type orphanedPlugin struct {
+	Plugin  string `json:"plugin"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+	// Replacement is the display name of the plugin that replaces it.
+	Replacement string `json:"replacement,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+
+	name        string
+	indexName   string
+	replacement string
+}
+
+func (o orphanedPlugin) String() string {
+	var s string
+	switch o.Status {
+	case orphanRenamed:
+		s = fmt.Sprintf("%s %s was renamed to %s", o.Plugin, o.Version, o.Replacement)
+	case orphanDeprecated:
+		s = fmt.Sprintf("%s %s is deprecated", o.Plugin, o.Version)
+		if o.Replacement != "" {
+			s += fmt.Sprintf(", use %s instead", o.Replacement)
+		}
+	default:
+		s = fmt.Sprintf("%s %s was removed from its index", o.Plugin, o.Version)
+	}
+	if o.Reason != "" {
+		s += ": " + o.Reason
+	}
+	return s
+}
+
+// findOrphanedPlugins returns the installed plugins of the receipts that are
+// missing from their index, or that their index lists as retired. Catalogs
+// are loaded into catalogs as with loadIndexPlugin. Plugins of indexes that
+// no longer exist or can't be read are skipped.
+func findOrphanedPlugins(receipts []index.Receipt, catalogs map[string]*indexoperations.Catalog) []orphanedPlugin {
+	retired := make(map[string]map[string]indexoperations.RetiredPlugin)
+	var out []orphanedPlugin
+	for _, r := range receipts {
+		indexName := indexOf(r)
+		if indexName == "detached" {
+			continue
+		}
+		if _, err := os.Stat(paths.IndexPath(indexName)); err != nil {
+			klog.V(1).Infof("Skipping %q: cannot read its index %q: %v", r.Name, indexName, err)
+			continue
+		}
+		retiredPlugins, ok := retired[indexName]
+		if !ok {
+			var err error
+			if retiredPlugins, err = indexoperations.RetiredPlugins(paths, indexName); err != nil {
+				klog.Warningf("failed to load the retired plugins of index %q: %v", indexName, err)
+			}
+			retired[indexName] = retiredPlugins
+		}
+
+		_, err := loadIndexPlugin(catalogs, indexName, r.Name)
+		if err != nil && !os.IsNotExist(err) {
+			klog.V(1).Infof("Skipping %q: failed to load it from index %q: %v", r.Name, indexName, err)
+			continue
+		}
+		entry, isRetired := retiredPlugins[r.Name]
+		o := orphanedPlugin{
+			Plugin:      displayName(r.Plugin, indexName),
+			Version:     r.Spec.Version,
+			Reason:      entry.Reason,
+			name:        r.Name,
+			indexName:   indexName,
+			replacement: entry.Replacement,
+		}
+		switch {
+		case err == nil && !isRetired:
+			continue
+		case err == nil:
+			o.Status = orphanDeprecated
+		case entry.Replacement != "":
+			o.Status = orphanRenamed
+		default:
+			o.Status = orphanRemoved
+		}
+		if entry.Replacement != "" {
+			replacement := index.Plugin{}
+			replacement.Name = entry.Replacement
+			o.Replacement = displayName(replacement, indexName)
+		}
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Plugin < out[j].Plugin })
+	return out
+}
+
+// printOrphanedPlugins warns about the orphaned plugins, if there are any.
+func printOrphanedPlugins(out io.Writer, orphans []orphanedPlugin) {
+	if len(orphans) == 0 {
+		return
+	}
+	internal.PrintWarning(out, "Some installed plugins are no longer maintained in their index:\n")
+	var replaced bool
+	for _, o := range orphans {
+		fmt.Fprintf(out, "  * %s\n", o)
+		replaced = replaced || o.replacement != ""
+	}
+	if replaced {
+		fmt.Fprintln(out, `To install their replacements, run "kubectl krew upgrade --migrate".`)
+	}
+}
+
+// migratePlugin installs the replacement of the orphaned plugin from the same
+// index, and uninstalls the plugin.
+func migratePlugin(o orphanedPlugin, pol policy.Set, indexes map[string]string, opts installation.InstallOpts) error {
+	plugin, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath(o.indexName), o.replacement)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the plugin manifest of replacement %q", o.Replacement)
+	}
+	if err := checkPluginPolicy(pol, indexes, o.indexName, plugin).Err(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Migrating plugin %s to %s\n", o.Plugin, o.Replacement)
+	err = installation.Install(paths, plugin, o.indexName, opts)
+	if err == installation.ErrIsAlreadyInstalled {
+		klog.V(1).Infof("Replacement %q is already installed", plugin.Name)
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to install replacement %q", o.Replacement)
+	} else {
+		fmt.Fprintf(os.Stderr, "Installed plugin: %s\n", plugin.Name)
+		auditInstalledPlugin(auditlog.ActionInstall, plugin.Name, "")
+		if o.indexName == constants.DefaultIndexName {
+			internal.PrintSecurityNotice(plugin.Name)
+		}
+	}
+
+	r, receiptErr := receipt.Load(paths.PluginInstallReceiptPath(o.name))
+	if err := installation.Uninstall(paths, o.name); err != nil {
+		return errors.Wrapf(err, "failed to uninstall plugin %s", o.name)
+	}
+	fmt.Fprintf(os.Stderr, "Uninstalled plugin: %s\n", o.name)
+	if receiptErr == nil {
+		auditPlugin(auditlog.ActionUninstall, r, "")
+	}
+	return nil
+}
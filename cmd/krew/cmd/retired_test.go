@@ -0,0 +1,81 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func Test_findOrphanedPlugins(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	defer func(p environment.Paths) { paths = p }(paths)
+	paths = environment.NewPaths(tmpDir.Root())
+
+	tmpDir.WriteYAML("index/default/plugins/kept.yaml", testutil.NewPlugin().WithName("kept").V())
+	tmpDir.WriteYAML("index/default/plugins/old.yaml", testutil.NewPlugin().WithName("old").V())
+	tmpDir.WriteYAML("index/default/plugins/new.yaml", testutil.NewPlugin().WithName("new").V())
+	tmpDir.Write("index/default/retired.yaml", []byte(`plugins:
+- name: old
+  replacement: new
+- name: renamed
+  replacement: new
+  reason: moved to a new repository
+`))
+	tmpDir.WriteYAML("index/foo/plugins/kept.yaml", testutil.NewPlugin().WithName("kept").V())
+
+	receipt := func(name, indexName string) index.Receipt {
+		return testutil.NewReceipt().WithPlugin(testutil.NewPlugin().WithName(name).WithVersion("v1.0.0").V()).
+			WithStatus(index.ReceiptStatus{Source: index.SourceIndex{Name: indexName}}).V()
+	}
+	receipts := []index.Receipt{
+		receipt("kept", "default"),
+		receipt("old", "default"),
+		receipt("renamed", "default"),
+		receipt("removed", "foo"),
+		receipt("kept", "foo"),
+		receipt("gone", "missing-index"),
+		receipt("manifest", "detached"),
+	}
+
+	got := findOrphanedPlugins(receipts, make(map[string]*indexoperations.Catalog))
+	expected := []orphanedPlugin{
+		{Plugin: "foo/removed", Version: "v1.0.0", Status: orphanRemoved, name: "removed", indexName: "foo"},
+		{Plugin: "old", Version: "v1.0.0", Status: orphanDeprecated, Replacement: "new", name: "old", indexName: "default", replacement: "new"},
+		{Plugin: "renamed", Version: "v1.0.0", Status: orphanRenamed, Replacement: "new", Reason: "moved to a new repository", name: "renamed", indexName: "default", replacement: "new"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(orphanedPlugin{})); diff != "" {
+		t.Errorf("findOrphanedPlugins() mismatch (-want +got):\n%s", diff)
+	}
+
+	var out bytes.Buffer
+	printOrphanedPlugins(&out, got)
+	expectedOut := `WARNING: Some installed plugins are no longer maintained in their index:
+  * foo/removed v1.0.0 was removed from its index
+  * old v1.0.0 is deprecated, use new instead
+  * renamed v1.0.0 was renamed to new: moved to a new repository
+To install their replacements, run "kubectl krew upgrade --migrate".
+`
+	if diff := cmp.Diff(expectedOut, out.String()); diff != "" {
+		t.Errorf("printOrphanedPlugins() mismatch (-want +got):\n%s", diff)
+	}
+}
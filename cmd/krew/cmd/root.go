@@ -31,7 +31,7 @@ import (
 
 	"sigs.k8s.io/krew/cmd/krew/cmd/internal"
 	"sigs.k8s.io/krew/internal/environment"
-	"sigs.k8s.io/krew/internal/gitutil"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/indexmigration"
 	"sigs.k8s.io/krew/internal/installation"
 	"sigs.k8s.io/krew/internal/installation/receipt"
@@ -166,6 +166,16 @@ func preRun(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	isMigrated, err = installation.ReceiptsUpgraded(paths)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if plugin receipts are upgraded")
+	}
+	if !isMigrated {
+		if err := installation.UpgradeReceipts(paths); err != nil {
+			klog.Warningf("Failed to upgrade plugin receipts: %v", err)
+		}
+	}
+
 	if installation.IsWindows() {
 		klog.V(4).Infof("detected windows, will check for old krew installations to clean up")
 		err := cleanupStaleKrewInstallations()
@@ -223,14 +233,11 @@ func checkIndex(_ *cobra.Command, _ []string) error {
 		return errors.New(`krew local plugin index is not initialized (run "kubectl krew update")`)
 	}
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
 			continue
 		}
-		indexPath := paths.IndexPath(e.Name())
-		if ok, err := gitutil.IsGitCloned(indexPath); err != nil {
-			return errors.Wrap(err, "failed to check local index git repository")
-		} else if !ok {
-			return errors.Errorf("invalid index %q, non git directory found in index folder", e.Name())
+		if _, err := indexoperations.IndexBackend(paths, e.Name()); err != nil {
+			return err
 		}
 	}
 	return nil
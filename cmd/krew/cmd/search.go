@@ -17,7 +17,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"runtime"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -26,7 +25,6 @@ import (
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/krew/internal/index/indexoperations"
-	"sigs.k8s.io/krew/internal/index/indexscanner"
 	"sigs.k8s.io/krew/internal/installation"
 )
 
@@ -67,7 +65,7 @@ Examples:
   To fuzzy search plugins with a keyword:
     kubectl krew search KEYWORD`,
 	RunE: func(_ *cobra.Command, args []string) error {
-		indexes, err := indexoperations.ListIndexes(paths)
+		indexes, err := indexoperations.PrioritizedIndexes(paths)
 		if err != nil {
 			return errors.Wrap(err, "failed to list indexes")
 		}
@@ -75,13 +73,21 @@ Examples:
 		klog.V(3).Infof("found %d indexes", len(indexes))
 
 		var plugins []pluginEntry
+		// resolvedIndex maps plugin names to the index that the name without
+		// an index refers to, which is the first one in priority order.
+		resolvedIndex := make(map[string]string)
+		catalogs := make(map[string]*indexoperations.Catalog, len(indexes))
 		for _, idx := range indexes {
-			ps, err := indexscanner.LoadPluginListFromFS(paths.IndexPluginsPath(idx.Name))
+			catalog, err := indexoperations.LoadCatalog(paths, idx.Name)
 			if err != nil {
 				return errors.Wrapf(err, "failed to load the list of plugins from the index %q", idx.Name)
 			}
-			for _, p := range ps {
+			catalogs[idx.Name] = catalog
+			for _, p := range catalog.Plugins() {
 				plugins = append(plugins, pluginEntry{p, idx.Name})
+				if _, ok := resolvedIndex[p.Name]; !ok {
+					resolvedIndex[p.Name] = idx.Name
+				}
 			}
 		}
 
@@ -116,20 +122,25 @@ Examples:
 
 		var rows [][]string
 		cols := []string{"NAME", "DESCRIPTION", "INSTALLED"}
+		env := installation.OSArch()
 		for _, canonicalName := range searchResults {
 			v := pluginCanonicalNameMap[canonicalName]
 			var status string
 			if installed[canonicalName] {
 				status = "yes"
-			} else if _, ok, err := installation.GetMatchingPlatform(v.p.Spec.Platforms); err != nil {
+			} else if ok, err := catalogs[v.indexName].Available(v.p.Name, env.OS, env.Arch); err != nil {
 				return errors.Wrapf(err, "failed to get the matching platform for plugin %s", canonicalName)
 			} else if ok {
 				status = "no"
 			} else {
-				status = fmt.Sprintf("unavailable on %v/%v", runtime.GOOS, runtime.GOARCH)
+				status = fmt.Sprintf("unavailable on %v", env)
 			}
 
-			rows = append(rows, []string{displayName(v.p, v.indexName), limitString(v.p.Spec.ShortDescription, 50), status})
+			description := limitString(v.p.Spec.ShortDescription, 50)
+			if idx := resolvedIndex[v.p.Name]; idx != v.indexName {
+				description = fmt.Sprintf("(shadowed by %s/%s) %s", idx, v.p.Name, description)
+			}
+			rows = append(rows, []string{displayName(v.p, v.indexName), description, status})
 		}
 		rows = sortByFirstColumn(rows)
 		return printTable(os.Stdout, cols, rows)
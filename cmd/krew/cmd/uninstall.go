@@ -22,8 +22,10 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
+	"sigs.k8s.io/krew/internal/auditlog"
 	"sigs.k8s.io/krew/internal/index/validation"
 	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/installation/receipt"
 )
 
 // uninstallCmd represents the uninstall command
@@ -45,10 +47,14 @@ Remarks:
 				return unsafePluginNameErr(name)
 			}
 			klog.V(4).Infof("Going to uninstall plugin %s\n", name)
+			r, receiptErr := receipt.Load(paths.PluginInstallReceiptPath(name))
 			if err := installation.Uninstall(paths, name); err != nil {
 				return errors.Wrapf(err, "failed to uninstall plugin %s", name)
 			}
 			fmt.Fprintf(os.Stderr, "Uninstalled plugin: %s\n", name)
+			if receiptErr == nil {
+				auditPlugin(auditlog.ActionUninstall, r, "")
+			}
 		}
 		return nil
 	},
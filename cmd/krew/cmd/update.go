@@ -16,19 +16,26 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/klog/v2"
 
-	"sigs.k8s.io/krew/internal/gitutil"
+	"sigs.k8s.io/krew/cmd/krew/cmd/internal"
+	"sigs.k8s.io/krew/internal/auditlog"
 	"sigs.k8s.io/krew/internal/index/indexoperations"
-	"sigs.k8s.io/krew/internal/index/indexscanner"
 	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/policy"
 	"sigs.k8s.io/krew/pkg/constants"
 	"sigs.k8s.io/krew/pkg/index"
 )
@@ -42,10 +49,50 @@ var updateCmd = &cobra.Command{
 This command synchronizes the local copy of the plugin manifests with the
 plugin index from the internet.
 
+Indexes are updated concurrently. The update of each index is stopped after
+the time in the KREW_INDEX_UPDATE_TIMEOUT environment variable (such as "30s",
+default 2m, or 0 for no timeout).
+
+After the update, new plugins are listed, as well as upgrades, removals and
+other changes to the manifests of installed plugins, and installed plugins
+that are no longer maintained in their index. Use "-o json" to print these
+changes as JSON.
+
 Remarks:
   You don't need to run this command: Running "krew update" or "krew upgrade"
-  will silently run this command.`,
-	RunE: ensureIndexes,
+  will silently run this command. Unlike this command, they carry on with the
+  last local copy of an index that fails to update.`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if updateOutput != "text" && updateOutput != "json" {
+			return errors.Errorf("invalid output format %q, must be text or json", updateOutput)
+		}
+		if err := ensureDefaultIndexIfNoneExist(); err != nil {
+			return err
+		}
+		return ensureIndexesUpdated(true, updateOutput)
+	},
+}
+
+// updateOutput is the output format of the changes to plugins printed by the
+// update command.
+var updateOutput string
+
+// defaultIndexUpdateTimeout is the time allowed for updating each index if
+// KREW_INDEX_UPDATE_TIMEOUT is not set.
+const defaultIndexUpdateTimeout = 2 * time.Minute
+
+// indexUpdateTimeout returns the time allowed for updating each index, or 0
+// if updates have no timeout.
+func indexUpdateTimeout() (time.Duration, error) {
+	v := os.Getenv("KREW_INDEX_UPDATE_TIMEOUT")
+	if v == "" {
+		return defaultIndexUpdateTimeout, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, errors.Errorf("invalid KREW_INDEX_UPDATE_TIMEOUT value %q, must be a non-negative duration such as 30s", v)
+	}
+	return d, nil
 }
 
 func showFormattedPluginsInfo(out io.Writer, header string, plugins []string) {
@@ -59,49 +106,166 @@ func showFormattedPluginsInfo(out io.Writer, header string, plugins []string) {
 	fmt.Fprintf(out, "%s", b.String())
 }
 
-func showUpdatedPlugins(out io.Writer, preUpdate, postUpdate []pluginEntry, installedPlugins map[string]string) {
-	var newPlugins []pluginEntry
-	var updatedPlugins []pluginEntry
+// updateReport lists the changes to plugins made by an update of the indexes.
+// Apart from new plugins, only changes to installed plugins are listed.
+type updateReport struct {
+	New      []string        `json:"new"`
+	Upgrades []pluginUpgrade `json:"upgrades"`
+	// Removed lists plugins that were removed from their index.
+	Removed []string `json:"removed"`
+	// ManifestChanged lists plugins whose download changed without a new
+	// version.
+	ManifestChanged []pluginChange `json:"manifestChanged"`
+	// DescriptionChanged lists plugins whose description or caveats changed.
+	DescriptionChanged []pluginChange `json:"descriptionChanged"`
+	// Orphaned lists all installed plugins that are missing from their index
+	// or retired by it, including the ones removed by this update.
+	Orphaned []orphanedPlugin `json:"orphaned"`
+}
+
+type pluginUpgrade struct {
+	Plugin string `json:"plugin"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type pluginChange struct {
+	Plugin  string `json:"plugin"`
+	Version string `json:"version"`
+	// Fields are the changed fields of the manifest: "uri", "sha256",
+	// "sha512", "mirrors" or "platform" (no longer available on this
+	// platform) for manifest changes,
+	// "description" or "caveats" for description changes.
+	Fields []string `json:"fields"`
+}
+
+// newUpdateReport compares the plugins before and after an update.
+// installedPlugins maps the canonical names of installed plugins to their
+// version.
+func newUpdateReport(preUpdate, postUpdate []pluginEntry, installedPlugins map[string]string) updateReport {
+	r := updateReport{
+		New:                []string{},
+		Upgrades:           []pluginUpgrade{},
+		Removed:            []string{},
+		ManifestChanged:    []pluginChange{},
+		DescriptionChanged: []pluginChange{},
+		Orphaned:           []orphanedPlugin{},
+	}
 
 	oldIndexMap := make(map[string]pluginEntry)
 	for _, p := range preUpdate {
 		oldIndexMap[canonicalName(p.p, p.indexName)] = p
 	}
-
+	newIndexMap := make(map[string]bool)
 	for _, p := range postUpdate {
 		cName := canonicalName(p.p, p.indexName)
+		newIndexMap[cName] = true
+		name := displayName(p.p, p.indexName)
 		old, ok := oldIndexMap[cName]
 		if !ok {
-			newPlugins = append(newPlugins, p)
+			r.New = append(r.New, name)
 			continue
 		}
-
 		if _, ok := installedPlugins[cName]; !ok {
 			continue
 		}
 
 		if old.p.Spec.Version != p.p.Spec.Version {
-			updatedPlugins = append(updatedPlugins, p)
+			r.Upgrades = append(r.Upgrades, pluginUpgrade{Plugin: name, From: old.p.Spec.Version, To: p.p.Spec.Version})
+		} else if fields := manifestChanges(old.p, p.p); len(fields) > 0 {
+			r.ManifestChanged = append(r.ManifestChanged, pluginChange{Plugin: name, Version: p.p.Spec.Version, Fields: fields})
+		}
+		if fields := descriptionChanges(old.p, p.p); len(fields) > 0 {
+			r.DescriptionChanged = append(r.DescriptionChanged, pluginChange{Plugin: name, Version: p.p.Spec.Version, Fields: fields})
 		}
 	}
-
-	if len(newPlugins) > 0 {
-		var s []string
-		for _, p := range newPlugins {
-			s = append(s, displayName(p.p, p.indexName))
+	for _, p := range preUpdate {
+		cName := canonicalName(p.p, p.indexName)
+		if _, ok := installedPlugins[cName]; ok && !newIndexMap[cName] {
+			r.Removed = append(r.Removed, displayName(p.p, p.indexName))
 		}
-		showFormattedPluginsInfo(out, "New plugins available", s)
 	}
+	return r
+}
 
-	if len(updatedPlugins) > 0 {
+// manifestChanges returns the changed fields of the download of a plugin for
+// this platform.
+func manifestChanges(old, cur index.Plugin) []string {
+	oldPlatform, ok, err := installation.GetMatchingPlatform(old.Spec.Platforms)
+	if err != nil || !ok {
+		return nil
+	}
+	curPlatform, ok, err := installation.GetMatchingPlatform(cur.Spec.Platforms)
+	if err != nil || !ok {
+		return []string{"platform"}
+	}
+	var fields []string
+	if oldPlatform.URI != curPlatform.URI {
+		fields = append(fields, "uri")
+	}
+	if oldPlatform.Sha256 != curPlatform.Sha256 {
+		fields = append(fields, "sha256")
+	}
+	if oldPlatform.Sha512 != curPlatform.Sha512 {
+		fields = append(fields, "sha512")
+	}
+	if !slices.Equal(oldPlatform.Mirrors, curPlatform.Mirrors) {
+		fields = append(fields, "mirrors")
+	}
+	return fields
+}
+
+func descriptionChanges(old, cur index.Plugin) []string {
+	var fields []string
+	if old.Spec.ShortDescription != cur.Spec.ShortDescription || old.Spec.Description != cur.Spec.Description {
+		fields = append(fields, "description")
+	}
+	if old.Spec.Caveats != cur.Spec.Caveats {
+		fields = append(fields, "caveats")
+	}
+	return fields
+}
+
+func (r updateReport) print(out io.Writer) {
+	if len(r.New) > 0 {
+		showFormattedPluginsInfo(out, "New plugins available", r.New)
+	}
+	if len(r.Upgrades) > 0 {
 		var s []string
-		for _, p := range updatedPlugins {
-			old := oldIndexMap[canonicalName(p.p, p.indexName)]
-			name := displayName(p.p, p.indexName)
-			s = append(s, fmt.Sprintf("%s %s -> %s", name, old.p.Spec.Version, p.p.Spec.Version))
+		for _, u := range r.Upgrades {
+			s = append(s, fmt.Sprintf("%s %s -> %s", u.Plugin, u.From, u.To))
 		}
 		showFormattedPluginsInfo(out, "Upgrades available for installed plugins", s)
 	}
+	if len(r.Removed) > 0 {
+		showFormattedPluginsInfo(out, "Installed plugins removed from their index", r.Removed)
+	}
+	if len(r.ManifestChanged) > 0 {
+		showFormattedPluginsInfo(out, "Installed plugins changed without a new version", formatChanges(r.ManifestChanged))
+	}
+	if len(r.DescriptionChanged) > 0 {
+		showFormattedPluginsInfo(out, "Descriptions changed for installed plugins", formatChanges(r.DescriptionChanged))
+	}
+	// Plugins removed by this update are already listed above.
+	removed := make(map[string]bool)
+	for _, name := range r.Removed {
+		removed[name] = true
+	}
+	var orphans []orphanedPlugin
+	for _, o := range r.Orphaned {
+		if !removed[o.Plugin] || o.Status != orphanRemoved {
+			orphans = append(orphans, o)
+		}
+	}
+	printOrphanedPlugins(out, orphans)
+}
+
+func formatChanges(changes []pluginChange) []string {
+	var s []string
+	for _, c := range changes {
+		s = append(s, fmt.Sprintf("%s %s (%s changed)", c.Plugin, c.Version, strings.Join(c.Fields, ", ")))
+	}
+	return s
 }
 
 // loadPlugins loads plugin entries from specified indexes. Parse errors
@@ -109,69 +273,145 @@ func showUpdatedPlugins(out io.Writer, preUpdate, postUpdate []pluginEntry, inst
 func loadPlugins(indexes []indexoperations.Index) []pluginEntry {
 	var out []pluginEntry
 	for _, idx := range indexes {
-		list, err := indexscanner.LoadPluginListFromFS(paths.IndexPluginsPath(idx.Name))
+		catalog, err := indexoperations.LoadCatalog(paths, idx.Name)
 		if err != nil {
 			klog.V(1).Infof("WARNING: failed to load plugin list from %q: %v", idx.Name, err)
 			continue
 		}
-		for _, v := range list {
+		for _, v := range catalog.Plugins() {
 			out = append(out, pluginEntry{indexName: idx.Name, p: v})
 		}
 	}
 	return out
 }
 
+// loadIndexPlugin loads the named plugin from the catalog of the index, and
+// keeps the catalog in catalogs for further lookups. If the index or the
+// plugin does not exist, it returns an error that can be checked with
+// os.IsNotExist.
+func loadIndexPlugin(catalogs map[string]*indexoperations.Catalog, indexName, name string) (index.Plugin, error) {
+	c, ok := catalogs[indexName]
+	if !ok {
+		var err error
+		if c, err = indexoperations.LoadCatalog(paths, indexName); err != nil {
+			return index.Plugin{}, err
+		}
+		catalogs[indexName] = c
+	}
+	return c.Plugin(name)
+}
+
+// ensureIndexes adds the required and default indexes if missing and updates
+// all indexes. Indexes that fail to update are used at their last local copy.
 func ensureIndexes(_ *cobra.Command, _ []string) error {
 	klog.V(3).Infof("Will check if there are any indexes added.")
 	if err := ensureDefaultIndexIfNoneExist(); err != nil {
 		return err
 	}
-	return ensureIndexesUpdated()
+	return ensureIndexesUpdated(false, "text")
 }
 
-// ensureDefaultIndexIfNoneExist adds the default index automatically
-// (and informs the user about it) if no plugin index exists for krew.
+// ensureDefaultIndexIfNoneExist adds the indexes required by the policy that
+// are missing, and then adds the default index automatically (and informs the
+// user about it) if no plugin index exists for krew. Indexes that the policy
+// doesn't allow are not added.
 func ensureDefaultIndexIfNoneExist() error {
+	pol, err := policy.Load()
+	if err != nil {
+		return err
+	}
 	idx, err := indexoperations.ListIndexes(paths)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve plugin indexes")
 	}
-	if len(idx) > 0 {
-		klog.V(3).Infof("Found %d indexes, skipping adding default index.", len(idx))
+	existing := make(map[string]bool, len(idx))
+	for _, i := range idx {
+		existing[i.Name] = true
+	}
+	for _, r := range pol.RequiredIndexes() {
+		if existing[r.Name] {
+			continue
+		}
+		if err := pol.CheckIndex(r.Name, r.URL).Err(); err != nil {
+			internal.PrintWarning(os.Stderr, "Not adding required plugin index %q: %v\n", r.Name, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Adding required plugin index %q from %s.\n", r.Name, r.URL)
+		if err := indexoperations.AddIndex(paths, r.Name, r.URL, indexoperations.IndexConfig{}); err != nil {
+			return errors.Wrapf(err, "failed to add required plugin index %q", r.Name)
+		}
+		auditIndex(auditlog.ActionIndexAdd, r.Name, r.URL, "")
+		existing[r.Name] = true
+	}
+	if len(existing) > 0 {
+		klog.V(3).Infof("Found %d indexes, skipping adding default index.", len(existing))
 		return nil
 	}
 
 	klog.V(3).Infof("No index found, add default index.")
 	defaultIndex := index.DefaultIndex()
+	if err := pol.CheckIndex(constants.DefaultIndexName, defaultIndex).Err(); err != nil {
+		return errors.Wrap(err, "no plugin index exists and the default index can't be added")
+	}
 	fmt.Fprintf(os.Stderr, "Adding \"default\" plugin index from %s.\n", defaultIndex)
-	return errors.Wrap(indexoperations.AddIndex(paths, constants.DefaultIndexName, defaultIndex),
-		"failed to add default plugin index in absence of no indexes")
+	if err := indexoperations.AddIndex(paths, constants.DefaultIndexName, defaultIndex, indexoperations.IndexConfig{}); err != nil {
+		return errors.Wrap(err, "failed to add default plugin index in absence of no indexes")
+	}
+	auditIndex(auditlog.ActionIndexAdd, constants.DefaultIndexName, defaultIndex, "")
+	return nil
 }
 
-// ensureIndexesUpdated iterates over all indexes and updates them
-// and prints new plugins and upgrades available for installed plugins.
-func ensureIndexesUpdated() error {
+// ensureIndexesUpdated updates all indexes concurrently and prints the
+// changes to plugins, as text or, if output is "json", as JSON to stdout. For
+// indexes that fail to update, a warning with the age of their local copy is
+// printed, and an error is returned only if failOnError is set.
+func ensureIndexesUpdated(failOnError bool, output string) error {
 	indexes, err := indexoperations.ListIndexes(paths)
 	if err != nil {
 		return errors.Wrap(err, "failed to list indexes")
 	}
+	timeout, err := indexUpdateTimeout()
+	if err != nil {
+		return err
+	}
 
 	// collect list of existing plugins
 	preUpdatePlugins := loadPlugins(indexes)
 
+	fromCommits := make([]string, len(indexes))
+	updateErrs := make([]error, len(indexes))
+	var wg sync.WaitGroup
+	for i, idx := range indexes {
+		fromCommits[i] = indexCommit(idx.Name)
+		wg.Add(1)
+		go func(i int, idx indexoperations.Index) {
+			defer wg.Done()
+			ctx := context.Background()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			klog.V(1).Infof("Updating the local copy of plugin index (%s)", paths.IndexPath(idx.Name))
+			updateErrs[i] = indexoperations.UpdateIndex(ctx, paths, idx)
+		}(i, idx)
+	}
+	wg.Wait()
+
 	var failed []string
 	var returnErr error
-	for _, idx := range indexes {
-		indexPath := paths.IndexPath(idx.Name)
-		klog.V(1).Infof("Updating the local copy of plugin index (%s)", indexPath)
-		if err := gitutil.EnsureUpdated(idx.URL, indexPath); err != nil {
-			klog.Warningf("failed to update index %q: %v", idx.Name, err)
+	for i, idx := range indexes {
+		if err := updateErrs[i]; err != nil {
+			warnStaleIndex(idx.Name, err)
 			failed = append(failed, idx.Name)
 			if returnErr == nil {
 				returnErr = err
 			}
 			continue
 		}
+		if indexCommit(idx.Name) != fromCommits[i] {
+			auditIndex(auditlog.ActionIndexUpdate, idx.Name, idx.URL, fromCommits[i])
+		}
 
 		if isDefaultIndex(idx.Name) {
 			fmt.Fprintln(os.Stderr, "Updated the local copy of plugin index.")
@@ -180,22 +420,50 @@ func ensureIndexesUpdated() error {
 		}
 	}
 
+	receipts, err := installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
+	if err != nil {
+		return errors.Wrap(err, "failed to load installed plugins list after update")
+	}
+	// Without plugins before the update, such as when the first index was
+	// just added, every plugin would be listed as new.
+	report := newUpdateReport(nil, nil, nil)
 	if len(preUpdatePlugins) != 0 {
 		postUpdatePlugins := loadPlugins(indexes)
-
-		receipts, err := installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
-		if err != nil {
-			return errors.Wrap(err, "failed to load installed plugins list after update")
-		}
 		installedPlugins := make(map[string]string)
 		for _, receipt := range receipts {
 			installedPlugins[canonicalName(receipt.Plugin, indexOf(receipt))] = receipt.Spec.Version
 		}
-		showUpdatedPlugins(os.Stderr, preUpdatePlugins, postUpdatePlugins, installedPlugins)
+		report = newUpdateReport(preUpdatePlugins, postUpdatePlugins, installedPlugins)
+	}
+	if orphans := findOrphanedPlugins(receipts, make(map[string]*indexoperations.Catalog)); orphans != nil {
+		report.Orphaned = orphans
+	}
+	if output == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return errors.Wrap(err, "failed to print update report")
+		}
+	} else {
+		report.print(os.Stderr)
+	}
+	if !failOnError {
+		return nil
 	}
 	return errors.Wrapf(returnErr, "failed to update the following indexes: %s", strings.Join(failed, ", "))
 }
 
+// warnStaleIndex prints a warning that the named index failed to update and
+// how old its local copy is.
+func warnStaleIndex(name string, err error) {
+	age := "of unknown age"
+	if t, lastErr := indexoperations.LastUpdated(paths, name); lastErr == nil {
+		age = "last updated " + duration.HumanDuration(time.Since(t)) + " ago"
+	} else {
+		klog.V(1).Infof("failed to get last update time of index %q: %v", name, lastErr)
+	}
+	internal.PrintWarning(os.Stderr, "Failed to update index %q, using its local copy %s: %v\n", name, age, err)
+}
+
 func init() {
+	updateCmd.Flags().StringVarP(&updateOutput, "output", "o", "text", "Output format of the changes to plugins: text or json")
 	rootCmd.AddCommand(updateCmd)
 }
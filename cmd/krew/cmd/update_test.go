@@ -0,0 +1,135 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/testutil"
+)
+
+func Test_newUpdateReport(t *testing.T) {
+	platform := testutil.NewPlatform().WithOSArch(runtime.GOOS, runtime.GOARCH)
+	plugin := func(name, version string) *testutil.P {
+		return testutil.NewPlugin().WithName(name).WithVersion(version).WithPlatforms(platform.V())
+	}
+	withCaveats := plugin("caveats", "v1.0.0").V()
+	withCaveats.Spec.Caveats = "new caveats"
+
+	preUpdate := []pluginEntry{
+		{p: plugin("upgraded", "v1.0.0").V()},
+		{p: plugin("removed", "v1.0.0").V()},
+		{p: plugin("removed-not-installed", "v1.0.0").V()},
+		{p: plugin("rehashed", "v1.0.0").V()},
+		{p: plugin("rehashed-not-installed", "v1.0.0").V()},
+		{p: plugin("described", "v1.0.0").V(), indexName: "foo"},
+		{p: plugin("caveats", "v1.0.0").V()},
+		{p: plugin("unsupported", "v1.0.0").V()},
+		{p: plugin("unchanged", "v1.0.0").V()},
+		{p: plugin("resha512", "v1.0.0").V()},
+		{p: plugin("mirrored", "v1.0.0").V()},
+	}
+	rehashed := plugin("rehashed", "v1.0.0").WithPlatforms(testutil.NewPlatform().WithOSArch(runtime.GOOS, runtime.GOARCH).
+		WithURI("https://example.com/other.tar.gz").WithSHA256("0000000000000000000000000000000000000000000000000000000000000000").V()).V()
+	postUpdate := []pluginEntry{
+		{p: plugin("new", "v1.0.0").V()},
+		{p: plugin("upgraded", "v1.1.0").V()},
+		{p: plugin("removed-not-installed", "v1.0.0").V()},
+		{p: rehashed},
+		{p: plugin("rehashed-not-installed", "v1.0.0").WithPlatforms(testutil.NewPlatform().WithURI("https://example.com/other.tar.gz").V()).V()},
+		{p: plugin("described", "v1.0.0").WithShortDescription("new description").V(), indexName: "foo"},
+		{p: withCaveats},
+		{p: plugin("unsupported", "v1.0.0").WithPlatforms(testutil.NewPlatform().WithOSArch("none", "none").V()).V()},
+		{p: plugin("unchanged", "v1.0.0").V()},
+		{p: plugin("resha512", "v1.0.0").WithPlatforms(testutil.NewPlatform().WithOSArch(runtime.GOOS, runtime.GOARCH).WithSHA512("00").V()).V()},
+		{p: plugin("mirrored", "v1.0.0").WithPlatforms(testutil.NewPlatform().WithOSArch(runtime.GOOS, runtime.GOARCH).
+			WithMirrors("https://mirror.example.com/foo.tar.gz").V()).V()},
+	}
+	installed := map[string]string{
+		"default/upgraded":    "v1.0.0",
+		"default/removed":     "v1.0.0",
+		"default/rehashed":    "v1.0.0",
+		"foo/described":       "v1.0.0",
+		"default/caveats":     "v1.0.0",
+		"default/unsupported": "v1.0.0",
+		"default/unchanged":   "v1.0.0",
+		"default/resha512":    "v1.0.0",
+		"default/mirrored":    "v1.0.0",
+	}
+
+	got := newUpdateReport(preUpdate, postUpdate, installed)
+	expected := updateReport{
+		New:      []string{"new"},
+		Upgrades: []pluginUpgrade{{Plugin: "upgraded", From: "v1.0.0", To: "v1.1.0"}},
+		Removed:  []string{"removed"},
+		ManifestChanged: []pluginChange{
+			{Plugin: "rehashed", Version: "v1.0.0", Fields: []string{"uri", "sha256"}},
+			{Plugin: "unsupported", Version: "v1.0.0", Fields: []string{"platform"}},
+			{Plugin: "resha512", Version: "v1.0.0", Fields: []string{"sha512"}},
+			{Plugin: "mirrored", Version: "v1.0.0", Fields: []string{"mirrors"}},
+		},
+		DescriptionChanged: []pluginChange{
+			{Plugin: "foo/described", Version: "v1.0.0", Fields: []string{"description"}},
+			{Plugin: "caveats", Version: "v1.0.0", Fields: []string{"caveats"}},
+		},
+		Orphaned: []orphanedPlugin{},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("newUpdateReport() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Orphaned plugins removed by this update are only listed once.
+	got.Orphaned = []orphanedPlugin{
+		{Plugin: "removed", Version: "v1.0.0", Status: orphanRemoved},
+		{Plugin: "renamed", Version: "v1.0.0", Status: orphanRenamed, Replacement: "new", replacement: "new"},
+	}
+	var out bytes.Buffer
+	got.print(&out)
+	expectedOut := `  New plugins available:
+    * new
+  Upgrades available for installed plugins:
+    * upgraded v1.0.0 -> v1.1.0
+  Installed plugins removed from their index:
+    * removed
+  Installed plugins changed without a new version:
+    * rehashed v1.0.0 (uri, sha256 changed)
+    * unsupported v1.0.0 (platform changed)
+    * resha512 v1.0.0 (sha512 changed)
+    * mirrored v1.0.0 (mirrors changed)
+  Descriptions changed for installed plugins:
+    * foo/described v1.0.0 (description changed)
+    * caveats v1.0.0 (caveats changed)
+WARNING: Some installed plugins are no longer maintained in their index:
+  * renamed v1.0.0 was renamed to new
+To install their replacements, run "kubectl krew upgrade --migrate".
+`
+	if diff := cmp.Diff(expectedOut, out.String()); diff != "" {
+		t.Errorf("print() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_newUpdateReport_empty(t *testing.T) {
+	p := []pluginEntry{{p: testutil.NewPlugin().V()}}
+	got := newUpdateReport(p, p, map[string]string{"default/" + p[0].p.Name: p[0].p.Spec.Version})
+	var out bytes.Buffer
+	got.print(&out)
+	if out.Len() != 0 {
+		t.Errorf("expected no output for an update without changes, got %q", out.String())
+	}
+}
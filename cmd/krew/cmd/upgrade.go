@@ -23,16 +23,21 @@ import (
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/krew/cmd/krew/cmd/internal"
+	"sigs.k8s.io/krew/internal/auditlog"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/index/indexscanner"
 	"sigs.k8s.io/krew/internal/index/validation"
 	"sigs.k8s.io/krew/internal/installation"
 	"sigs.k8s.io/krew/internal/installation/receipt"
 	"sigs.k8s.io/krew/internal/pathutil"
+	"sigs.k8s.io/krew/internal/policy"
 	"sigs.k8s.io/krew/pkg/constants"
+	"sigs.k8s.io/krew/pkg/index"
 )
 
 func init() {
 	var noUpdateIndex *bool
+	var migrate *bool
 	var enableNetrc *bool
 	var netrcFile *string
 
@@ -52,12 +57,17 @@ func init() {
 This will reinstall all plugins that have a newer version in the local index.
 Use "kubectl krew update" to renew the index.
 To only upgrade single plugins provide them as arguments:
-kubectl krew upgrade foo bar"`,
+kubectl krew upgrade foo bar"
+
+Plugins that were renamed in or deprecated by their index are not replaced
+unless --migrate is given, which installs the replacement from the same index
+and uninstalls the plugin.`,
 		RunE: func(_ *cobra.Command, args []string) error {
 			var ignoreUpgraded bool
 			var skipErrors bool
 
 			var pluginNames []string
+			var receipts []index.Receipt
 			if len(args) == 0 {
 				// Upgrade all plugins.
 				installed, err := installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
@@ -67,6 +77,7 @@ kubectl krew upgrade foo bar"`,
 				for _, receipt := range installed {
 					pluginNames = append(pluginNames, receipt.Status.Source.Name+"/"+receipt.Name)
 				}
+				receipts = installed
 				ignoreUpgraded = true
 				skipErrors = true
 			} else {
@@ -82,9 +93,27 @@ kubectl krew upgrade foo bar"`,
 						return errors.Wrapf(err, "read receipt %q", arg)
 					}
 					pluginNames = append(pluginNames, r.Status.Source.Name+"/"+r.Name)
+					receipts = append(receipts, r)
 				}
 			}
+			orphans := make(map[string]orphanedPlugin)
+			for _, o := range findOrphanedPlugins(receipts, make(map[string]*indexoperations.Catalog)) {
+				orphans[o.name] = o
+			}
+
+			pol, err := policy.Load()
+			if err != nil {
+				return err
+			}
+			indexes, err := indexURLs()
+			if err != nil {
+				return err
+			}
 
+			installOpts := installation.InstallOpts{
+				EnableNetrc: *enableNetrc,
+				NetrcFile:   *netrcFile,
+			}
 			var nErrors int
 			for _, name := range pluginNames {
 				indexName, pluginName := pathutil.CanonicalPluginName(name)
@@ -93,6 +122,35 @@ kubectl krew upgrade foo bar"`,
 					continue
 				}
 
+				if o, ok := orphans[pluginName]; ok {
+					if *migrate && o.replacement != "" {
+						if err := migratePlugin(o, pol, indexes, installOpts); err != nil {
+							nErrors++
+							if skipErrors {
+								fmt.Fprintf(os.Stderr, "WARNING: failed to migrate plugin %q, skipping (error: %v)\n", o.Plugin, err)
+								continue
+							}
+							return errors.Wrapf(err, "failed to migrate plugin %q", o.Plugin)
+						}
+						fmt.Fprintf(os.Stderr, "Migrated plugin %s to %s\n", o.Plugin, o.Replacement)
+						continue
+					}
+					if o.Status == orphanDeprecated {
+						internal.PrintWarning(os.Stderr, "Plugin %s\n", o)
+					} else {
+						fmt.Fprintf(os.Stderr, "Skipping plugin: %s\n", o)
+					}
+					if o.replacement != "" {
+						fmt.Fprintf(os.Stderr, "To install %s instead, run \"kubectl krew upgrade --migrate %s\".\n", o.Replacement, o.name)
+					}
+					if o.Status != orphanDeprecated {
+						if !skipErrors {
+							return errors.Errorf("plugin %q does not exist in the plugin index", name)
+						}
+						continue
+					}
+				}
+
 				plugin, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath(indexName), pluginName)
 				if err != nil {
 					if !os.IsNotExist(err) {
@@ -103,12 +161,16 @@ kubectl krew upgrade foo bar"`,
 				}
 
 				pluginDisplayName := displayName(plugin, indexName)
+				var fromVersion string
 				if err == nil {
 					fmt.Fprintf(os.Stderr, "Upgrading plugin: %s\n", pluginDisplayName)
-					err = installation.Upgrade(paths, plugin, indexName, installation.InstallOpts{
-						EnableNetrc: *enableNetrc,
-						NetrcFile:   *netrcFile,
-					})
+					err = checkPluginPolicy(pol, indexes, indexName, plugin).Err()
+				}
+				if err == nil {
+					if r, err := receipt.Load(paths.PluginInstallReceiptPath(plugin.Name)); err == nil {
+						fromVersion = r.Spec.Version
+					}
+					err = installation.Upgrade(paths, plugin, indexName, installOpts)
 					if ignoreUpgraded && err == installation.ErrIsAlreadyUpgraded {
 						fmt.Fprintf(os.Stderr, "Skipping plugin %s, it is already on the newest version\n", pluginDisplayName)
 						continue
@@ -123,6 +185,7 @@ kubectl krew upgrade foo bar"`,
 					return errors.Wrapf(err, "failed to upgrade plugin %q", pluginDisplayName)
 				}
 				fmt.Fprintf(os.Stderr, "Upgraded plugin: %s\n", pluginDisplayName)
+				auditInstalledPlugin(auditlog.ActionUpgrade, plugin.Name, fromVersion)
 				if indexName == constants.DefaultIndexName {
 					internal.PrintSecurityNotice(plugin.Name)
 				}
@@ -141,6 +204,7 @@ kubectl krew upgrade foo bar"`,
 		},
 	}
 
+	migrate = upgradeCmd.Flags().Bool("migrate", false, "replace plugins renamed in or deprecated by their index with their replacement")
 	noUpdateIndex = upgradeCmd.Flags().Bool("no-update-index", false, "(Experimental) do not update local copy of plugin index before upgrading")
 	enableNetrc = upgradeCmd.Flags().Bool("enable-netrc", false, "read .netrc file for login credentials, used for downloading plugin packages")
 	netrcFile = upgradeCmd.Flags().String("netrc-file", defaultNetrcFile, "path to .netrc file for authentication (defaults to ~/.netrc or %HOME%/_netrc on Windows)")
@@ -0,0 +1,107 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/krew/internal/index/validation"
+	"sigs.k8s.io/krew/internal/installation"
+	"sigs.k8s.io/krew/internal/installation/receipt"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func init() {
+	// verifyCmd represents the verify command
+	verifyCmd := &cobra.Command{
+		Use:   "verify [PLUGIN...]",
+		Short: "Verify that installed plugins were not modified",
+		Long: `Verify that the files of installed plugins were not modified since they were
+installed.
+
+This command compares the installed files of each plugin with the checksums
+recorded in its receipt when it was installed, and reports missing, modified
+and added files. It also checks that the plugin executable link points to the
+installed plugin.
+
+The files of plugins installed by older versions of krew were recorded when
+their receipts were upgraded, not when they were installed. They are reported
+as "unverified" unless they fail verification. Reinstall these plugins to
+record their files.
+
+Remarks:
+  The command exits with a non-zero status if any plugin fails verification.`,
+		Example: `  kubectl krew verify
+  kubectl krew verify ctx ns`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			var receipts []index.Receipt
+			if len(args) == 0 {
+				var err error
+				receipts, err = installation.GetInstalledPluginReceipts(paths.InstallReceiptsPath())
+				if err != nil {
+					return errors.Wrap(err, "failed to find all installed versions")
+				}
+			}
+			for _, name := range args {
+				if isCanonicalName(name) {
+					return errors.New("verify command does not support INDEX/PLUGIN syntax; just specify PLUGIN")
+				} else if !validation.IsSafePluginName(name) {
+					return unsafePluginNameErr(name)
+				}
+				r, err := receipt.Load(paths.PluginInstallReceiptPath(name))
+				if os.IsNotExist(err) {
+					return errors.Errorf("plugin %q is not installed", name)
+				} else if err != nil {
+					return errors.Wrapf(err, "failed to load receipt of plugin %q", name)
+				}
+				receipts = append(receipts, r)
+			}
+
+			var rows [][]string
+			var failed int
+			for _, r := range receipts {
+				problems, err := installation.VerifyInstallation(paths, r)
+				if err != nil {
+					return errors.Wrapf(err, "failed to verify plugin %q", r.Name)
+				}
+				name := displayName(r.Plugin, indexOf(r))
+				if len(problems) == 0 && r.Status.UnverifiedBaseline {
+					rows = append(rows, []string{name, "unverified", "files were recorded after installation, reinstall the plugin to record them"})
+					continue
+				} else if len(problems) == 0 {
+					rows = append(rows, []string{name, "ok", ""})
+					continue
+				}
+				failed++
+				for _, problem := range problems {
+					rows = append(rows, []string{name, "failed", problem})
+				}
+			}
+			if err := printTable(os.Stdout, []string{"PLUGIN", "STATUS", "PROBLEM"}, rows); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return errors.Errorf("%d plugin(s) failed verification", failed)
+			}
+			return nil
+		},
+		PreRunE: checkIndex,
+	}
+
+	rootCmd.AddCommand(verifyCmd)
+}
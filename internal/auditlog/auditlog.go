@@ -0,0 +1,139 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auditlog implements an append-only log of plugin and index
+// operations, stored as one JSON object per line.
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Actions that are recorded in the audit log.
+const (
+	ActionInstall     = "install"
+	ActionUpgrade     = "upgrade"
+	ActionReinstall   = "reinstall"
+	ActionUninstall   = "uninstall"
+	ActionIndexAdd    = "index-add"
+	ActionIndexRemove = "index-remove"
+	ActionIndexUpdate = "index-update"
+)
+
+// Event is a single entry of the audit log.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	User   string    `json:"user,omitempty"`
+
+	Plugin      string `json:"plugin,omitempty"`
+	Version     string `json:"version,omitempty"`
+	FromVersion string `json:"fromVersion,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Sha256      string `json:"sha256,omitempty"`
+
+	Index           string `json:"index,omitempty"`
+	IndexURL        string `json:"indexURL,omitempty"`
+	IndexCommit     string `json:"indexCommit,omitempty"`
+	FromIndexCommit string `json:"fromIndexCommit,omitempty"`
+}
+
+// Append adds the event to the log at path, creating the file if needed. The
+// time and user of the event are filled in if they are unset.
+func Append(path string, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.User == "" {
+		e.User = currentUser()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode audit log event")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create audit log directory")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open audit log")
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write audit log")
+	}
+	return errors.Wrap(f.Close(), "failed to close audit log")
+}
+
+// Read returns the events in the log at path that match the filter, oldest
+// first. A missing log has no events.
+func Read(path string, filter Filter) ([]Event, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+	defer f.Close()
+
+	var out []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse line %d of audit log", line)
+		}
+		if filter.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out, errors.Wrap(scanner.Err(), "failed to read audit log")
+}
+
+// Filter selects events of the audit log. Empty fields match any event.
+type Filter struct {
+	Action string
+	Plugin string
+	Index  string
+	Since  time.Time
+}
+
+// Match reports whether the event is selected by the filter.
+func (f Filter) Match(e Event) bool {
+	return (f.Action == "" || f.Action == e.Action) &&
+		(f.Plugin == "" || f.Plugin == e.Plugin) &&
+		(f.Index == "" || f.Index == e.Index) &&
+		!e.Time.Before(f.Since)
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
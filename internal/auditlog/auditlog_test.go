@@ -0,0 +1,109 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/testutil"
+)
+
+func TestAppendRead(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	path := tmpDir.Path("log/audit.jsonl")
+
+	events, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read() of missing log failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events in missing log, got %v", events)
+	}
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := []Event{
+		{Time: t0, Action: ActionIndexAdd, User: "alice", Index: "default", IndexURL: "https://example.com/index.git", IndexCommit: "abc"},
+		{Time: t0.Add(time.Hour), Action: ActionInstall, User: "alice", Plugin: "foo", Version: "v1.0.0", URI: "https://example.com/foo.tar.gz", Sha256: "123", Index: "default", IndexCommit: "abc"},
+		{Time: t0.Add(2 * time.Hour), Action: ActionUninstall, User: "bob", Plugin: "foo", Version: "v1.0.0", Index: "default"},
+	}
+	for _, e := range want {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	got, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("events do not match: %s", diff)
+	}
+
+	got, err = Read(path, Filter{Plugin: "foo", Since: t0.Add(90 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if diff := cmp.Diff(want[2:], got); diff != "" {
+		t.Errorf("filtered events do not match: %s", diff)
+	}
+}
+
+func TestAppend_defaults(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	path := tmpDir.Path("audit.jsonl")
+	if err := Append(path, Event{Action: ActionInstall, Plugin: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Time.IsZero() {
+		t.Errorf("expected one event with time set, got %+v", got)
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	tmpDir.Write("audit.jsonl", []byte("{\"action\":\"install\"}\nnot json\n"))
+	if _, err := Read(tmpDir.Path("audit.jsonl"), Filter{}); err == nil {
+		t.Error("expected error reading invalid log")
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	e := Event{Time: time.Unix(100, 0), Action: ActionUpgrade, Plugin: "foo", Index: "corp"}
+	tests := []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{}, true},
+		{Filter{Action: ActionUpgrade, Plugin: "foo", Index: "corp"}, true},
+		{Filter{Action: ActionInstall}, false},
+		{Filter{Plugin: "bar"}, false},
+		{Filter{Index: "default"}, false},
+		{Filter{Since: time.Unix(100, 0)}, true},
+		{Filter{Since: time.Unix(101, 0)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(e); got != tt.want {
+			t.Errorf("%+v.Match() = %v, expected %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -18,15 +18,21 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"io"
 	"net/http"
 	"os"
+	pathpkg "path"
 	"path/filepath"
 	"strings"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
 	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/pathutil"
 )
 
 // download gets a file from the internet in memory and writes it content
@@ -55,15 +61,29 @@ func extractZIP(targetDir string, read io.ReaderAt, size int64) error {
 	if err != nil {
 		return err
 	}
+	// as with tar archives, all writes go through root to keep them within
+	// the target directory
+	root, err := os.OpenRoot(targetDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open target directory %q", targetDir)
+	}
+	defer root.Close()
+	budget, err := newExtractBudget()
+	if err != nil {
+		return err
+	}
 
 	for _, f := range zipReader.File {
 		if err := suspiciousPath(f.Name); err != nil {
 			return err
 		}
+		if err := budget.entry(); err != nil {
+			return err
+		}
 
-		path := filepath.Join(targetDir, filepath.FromSlash(f.Name))
+		path := filepath.Clean(filepath.FromSlash(f.Name))
 		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(path, f.Mode()); err != nil {
+			if err := root.MkdirAll(path, f.Mode().Perm()); err != nil {
 				return errors.Wrap(err, "can't create directory tree")
 			}
 			continue
@@ -71,7 +91,7 @@ func extractZIP(targetDir string, read io.ReaderAt, size int64) error {
 
 		dir := filepath.Dir(path)
 		klog.V(4).Infof("zip: ensuring parent dirs exist for regular file, dir=%s", dir)
-		if err := os.MkdirAll(dir, 0o755); err != nil {
+		if err := root.MkdirAll(dir, 0o755); err != nil {
 			return errors.Wrap(err, "failed to create directory for zip entry")
 		}
 		src, err := f.Open()
@@ -79,7 +99,7 @@ func extractZIP(targetDir string, read io.ReaderAt, size int64) error {
 			return errors.Wrap(err, "could not open inflating zip file")
 		}
 
-		dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode())
+		dst, err := root.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm())
 		if err != nil {
 			src.Close()
 			return errors.Wrap(err, "can't create file in zip destination dir")
@@ -89,7 +109,7 @@ func extractZIP(targetDir string, read io.ReaderAt, size int64) error {
 			dst.Close()
 		}
 
-		if _, err := io.Copy(dst, src); err != nil {
+		if err := budget.copy(dst, src, f.Name); err != nil {
 			closeAll()
 			return errors.Wrap(err, "can't copy content to zip destination file")
 		}
@@ -101,16 +121,61 @@ func extractZIP(targetDir string, read io.ReaderAt, size int64) error {
 
 // extractTARGZ extracts a gzipped tar file into the target directory.
 func extractTARGZ(targetDir string, at io.ReaderAt, size int64) error {
-	klog.V(4).Infof("tar: extracting to %q", targetDir)
-	in := io.NewSectionReader(at, 0, size)
-
-	gzr, err := gzip.NewReader(in)
+	gzr, err := gzip.NewReader(io.NewSectionReader(at, 0, size))
 	if err != nil {
 		return errors.Wrap(err, "failed to create gzip reader")
 	}
 	defer gzr.Close()
+	return untar(targetDir, gzr)
+}
+
+// extractTARXZ extracts a xz-compressed tar file into the target directory.
+func extractTARXZ(targetDir string, at io.ReaderAt, size int64) error {
+	xzr, err := xz.NewReader(io.NewSectionReader(at, 0, size))
+	if err != nil {
+		return errors.Wrap(err, "failed to create xz reader")
+	}
+	return untar(targetDir, xzr)
+}
+
+// extractTARBZ2 extracts a bzip2-compressed tar file into the target directory.
+func extractTARBZ2(targetDir string, at io.ReaderAt, size int64) error {
+	return untar(targetDir, bzip2.NewReader(io.NewSectionReader(at, 0, size)))
+}
+
+// extractTARZST extracts a zstd-compressed tar file into the target directory.
+func extractTARZST(targetDir string, at io.ReaderAt, size int64) error {
+	zr, err := zstd.NewReader(io.NewSectionReader(at, 0, size))
+	if err != nil {
+		return errors.Wrap(err, "failed to create zstd reader")
+	}
+	defer zr.Close()
+	return untar(targetDir, zr)
+}
 
-	tr := tar.NewReader(gzr)
+// extractTAR extracts an uncompressed tar file into the target directory.
+func extractTAR(targetDir string, at io.ReaderAt, size int64) error {
+	return untar(targetDir, io.NewSectionReader(at, 0, size))
+}
+
+// untar extracts the tar stream read from in into the target directory.
+// Symbolic and hard links are extracted if they resolve to a location within
+// the target directory.
+func untar(targetDir string, in io.Reader) error {
+	klog.V(4).Infof("tar: extracting to %q", targetDir)
+	// all writes go through root, so that links extracted earlier can't be
+	// used to place files outside of the target directory
+	root, err := os.OpenRoot(targetDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open target directory %q", targetDir)
+	}
+	defer root.Close()
+	budget, err := newExtractBudget()
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(in)
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -129,50 +194,134 @@ func extractTARGZ(targetDir string, at io.ReaderAt, size int64) error {
 		if err := suspiciousPath(hdr.Name); err != nil {
 			return err
 		}
+		if err := budget.entry(); err != nil {
+			return err
+		}
 
-		path := filepath.Join(targetDir, filepath.FromSlash(hdr.Name))
+		path := filepath.Clean(filepath.FromSlash(hdr.Name))
 		switch hdr.Typeflag {
 		case tar.TypeDir:
-			if err := os.MkdirAll(path, os.FileMode(hdr.Mode)); err != nil {
+			if err := root.MkdirAll(path, os.FileMode(hdr.Mode)); err != nil {
 				return errors.Wrap(err, "failed to create directory from tar")
 			}
 		case tar.TypeReg:
 			dir := filepath.Dir(path)
 			klog.V(4).Infof("tar: ensuring parent dirs exist for regular file, dir=%s", dir)
-			if err := os.MkdirAll(dir, 0o755); err != nil {
+			if err := root.MkdirAll(dir, 0o755); err != nil {
 				return errors.Wrap(err, "failed to create directory for tar")
 			}
-			f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, os.FileMode(hdr.Mode))
+			f, err := root.OpenFile(path, os.O_CREATE|os.O_WRONLY, os.FileMode(hdr.Mode))
 			if err != nil {
 				return errors.Wrapf(err, "failed to create file %q", path)
 			}
 
-			if _, err := io.Copy(f, tr); err != nil {
+			if err := budget.copy(f, tr, hdr.Name); err != nil {
 				f.Close()
 				return errors.Wrapf(err, "failed to copy %q from tar into file", hdr.Name)
 			}
 			f.Close()
+		case tar.TypeSymlink:
+			if err := suspiciousLink(hdr.Name, hdr.Linkname); err != nil {
+				return err
+			}
+			if err := root.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return errors.Wrap(err, "failed to create directory for tar")
+			}
+			if err := root.Symlink(hdr.Linkname, path); err != nil {
+				return errors.Wrapf(err, "failed to create symbolic link %q", path)
+			}
+		case tar.TypeLink:
+			// hard link names are relative to the root of the archive
+			if err := suspiciousPath(hdr.Linkname); err != nil {
+				return err
+			}
+			if err := root.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return errors.Wrap(err, "failed to create directory for tar")
+			}
+			if err := root.Link(filepath.FromSlash(hdr.Linkname), path); err != nil {
+				return errors.Wrapf(err, "failed to create hard link %q", path)
+			}
 		default:
 			return errors.Errorf("unable to handle file type %d for %q in tar", hdr.Typeflag, hdr.Name)
 		}
 		klog.V(4).Infof("tar: processed %q", hdr.Name)
 	}
+
+	// links are checked again once all entries exist, since the location a
+	// link resolves to depends on the links extracted after it
+	if err := pathutil.CheckSymlinks(targetDir); err != nil {
+		return errors.Wrap(err, "refusing to unpack archive with link pointing outside of it")
+	}
 	klog.V(4).Infof("tar extraction to %s complete", targetDir)
 	return nil
 }
 
+// suspiciousPath checks that the archive entry path stays within the directory
+// it is extracted to on any platform. Backslashes are treated as separators,
+// and absolute paths, drive letters, UNC paths and NUL bytes are rejected.
 func suspiciousPath(path string) error {
-	if strings.Contains(path, "..") {
-		return errors.Errorf("refusing to unpack archive with suspicious entry %q", path)
+	if strings.ContainsRune(path, 0) {
+		return errors.Errorf("refusing to unpack archive with entry %q containing a NUL byte", path)
 	}
-
-	if strings.HasPrefix(path, `/`) || strings.HasPrefix(path, `\`) {
+	p := toSlash(path)
+	if isAbsolute(p) {
 		return errors.Errorf("refusing to unpack archive with absolute entry %q", path)
 	}
+	if escapes(p) {
+		return errors.Errorf("refusing to unpack archive with entry %q outside of the target directory", path)
+	}
+	return nil
+}
 
+// suspiciousLink checks that the target of the symbolic link at path does not
+// point outside of the archive.
+func suspiciousLink(path, target string) error {
+	if strings.ContainsRune(target, 0) {
+		return errors.Errorf("refusing to unpack archive with symbolic link %q containing a NUL byte", path)
+	}
+	t := toSlash(target)
+	if isAbsolute(t) {
+		return errors.Errorf("refusing to unpack archive with symbolic link %q to absolute path %q", path, target)
+	}
+	if escapes(pathpkg.Join(pathpkg.Dir(toSlash(path)), t)) {
+		return errors.Errorf("refusing to unpack archive with symbolic link %q pointing outside of it (%q)", path, target)
+	}
 	return nil
 }
 
+// toSlash converts both kinds of separators to slashes regardless of the
+// host OS, since archives may come from any platform.
+func toSlash(p string) string { return strings.ReplaceAll(p, `\`, "/") }
+
+// isAbsolute reports whether the slash-separated path is rooted, or has a
+// drive letter, on any platform. UNC paths start with "//" and are rooted.
+func isAbsolute(p string) bool {
+	if strings.HasPrefix(p, "/") {
+		return true
+	}
+	return len(p) >= 2 && p[1] == ':' && (('a' <= p[0] && p[0] <= 'z') || ('A' <= p[0] && p[0] <= 'Z'))
+}
+
+// escapes reports whether the relative slash-separated path resolves to a
+// location outside of the directory it is relative to.
+func escapes(p string) bool {
+	clean := pathpkg.Clean(p)
+	return clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+// magicNumbers lists file signatures of the archive formats that
+// http.DetectContentType does not recognize.
+var magicNumbers = []struct {
+	offset   int
+	magic    []byte
+	mimeType string
+}{
+	{0, []byte{0xFD, '7', 'z', 'X', 'Z', 0x00}, "application/x-xz"},
+	{0, []byte("BZh"), "application/x-bzip2"},
+	{0, []byte{0x28, 0xB5, 0x2F, 0xFD}, "application/zstd"},
+	{257, []byte("ustar"), "application/x-tar"},
+}
+
 func detectMIMEType(at io.ReaderAt) (string, error) {
 	buf := make([]byte, 512)
 	n, err := at.ReadAt(buf, 0)
@@ -183,19 +332,38 @@ func detectMIMEType(at io.ReaderAt) (string, error) {
 		klog.V(5).Infof("Did only read %d of 512 bytes to determine the file type", n)
 	}
 
+	for _, m := range magicNumbers {
+		if n >= m.offset+len(m.magic) && bytes.Equal(buf[m.offset:m.offset+len(m.magic)], m.magic) {
+			return m.mimeType, nil
+		}
+	}
+
 	// Cut off mime extra info beginning with ';' i.e:
 	// "text/plain; charset=utf-8" should result in "text/plain".
 	return strings.Split(http.DetectContentType(buf[:n]), ";")[0], nil
 }
 
-type extractor func(targetDir string, read io.ReaderAt, size int64) error
+// Extractor unpacks the archive read from at into targetDir.
+type Extractor func(targetDir string, at io.ReaderAt, size int64) error
 
-var defaultExtractors = map[string]extractor{
-	"application/zip":    extractZIP,
-	"application/x-gzip": extractTARGZ,
+var defaultExtractors = map[string]Extractor{
+	"application/zip":     extractZIP,
+	"application/x-gzip":  extractTARGZ,
+	"application/x-xz":    extractTARXZ,
+	"application/x-bzip2": extractTARBZ2,
+	"application/zstd":    extractTARZST,
+	"application/x-tar":   extractTAR,
 }
 
-func extractArchive(dst string, at io.ReaderAt, size int64) error {
+// RegisterExtractor makes ExtractArchive use ex for files detected as
+// mimeType, replacing any extractor already registered for it.
+func RegisterExtractor(mimeType string, ex Extractor) {
+	defaultExtractors[mimeType] = ex
+}
+
+// ExtractArchive is an Extractor that detects the archive format from the
+// file contents and extracts it with the registered extractor.
+func ExtractArchive(dst string, at io.ReaderAt, size int64) error {
 	t, err := detectMIMEType(at)
 	if err != nil {
 		return errors.Wrap(err, "failed to determine content type")
@@ -208,6 +376,33 @@ func extractArchive(dst string, at io.ReaderAt, size int64) error {
 	return errors.Wrap(exf(dst, at, size), "failed to extract file")
 }
 
+// BinaryExtractor returns an Extractor that does not unpack the file, but
+// places it as an executable at the given slash-separated path in targetDir.
+func BinaryExtractor(name string) Extractor {
+	return func(targetDir string, at io.ReaderAt, size int64) error {
+		if err := suspiciousPath(name); err != nil {
+			return err
+		}
+		budget, err := newExtractBudget()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(targetDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return errors.Wrap(err, "failed to create directory for binary")
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create file %q", path)
+		}
+		if err := budget.copy(f, io.NewSectionReader(at, 0, size), name); err != nil {
+			f.Close()
+			return errors.Wrapf(err, "failed to write binary to %q", path)
+		}
+		return errors.Wrapf(f.Close(), "failed to close file %q", path)
+	}
+}
+
 // Downloader is responsible for fetching, verifying and extracting a binary.
 type Downloader struct {
 	verifier Verifier
@@ -229,5 +424,31 @@ func (d Downloader) Get(uri, dst string) error {
 	if err != nil {
 		return err
 	}
-	return extractArchive(dst, body, size)
+	return ExtractArchive(dst, body, size)
+}
+
+// GetFirst tries the uris in order until one of them can be fetched and
+// verified, and unpacks that file into dst with ex. A Verifier accumulates
+// state while reading, so newVerifier is called to get a fresh one for each
+// attempt. It returns the uri the file was served from. Extraction errors are
+// not retried, since every uri is expected to serve the same file.
+func GetFirst(uris []string, dst string, newVerifier func() (Verifier, error), fetcher Fetcher, ex Extractor) (string, error) {
+	if len(uris) == 0 {
+		return "", errors.New("no uri to download from")
+	}
+	var errs []string
+	for _, uri := range uris {
+		verifier, err := newVerifier()
+		if err != nil {
+			return "", err
+		}
+		body, size, err := download(uri, verifier, fetcher)
+		if err != nil {
+			klog.V(1).Infof("Download from %q failed: %v", uri, err)
+			errs = append(errs, err.Error())
+			continue
+		}
+		return uri, ex(dst, body, size)
+	}
+	return "", errors.Errorf("failed to download from all %d locations: %s", len(uris), strings.Join(errs, "; "))
 }
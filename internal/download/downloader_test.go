@@ -23,11 +23,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
 
+	"sigs.k8s.io/krew/internal/pathutil"
 	"sigs.k8s.io/krew/internal/testutil"
 )
 
@@ -142,6 +144,122 @@ func Test_extractTARGZ(t *testing.T) {
 	}
 }
 
+func TestExtractArchive_formats(t *testing.T) {
+	tests := []struct {
+		in    string
+		files []string
+	}{
+		{
+			in:    "test-flat-hierarchy.tar",
+			files: []string{"/foo"},
+		},
+		{
+			in:    "test-flat-hierarchy.tar.xz",
+			files: []string{"/foo"},
+		},
+		{
+			in:    "test-flat-hierarchy.tar.bz2",
+			files: []string{"/foo"},
+		},
+		{
+			in:    "test-flat-hierarchy.tar.zst",
+			files: []string{"/foo"},
+		},
+		{
+			in:    "test-with-directory-entry.tar",
+			files: []string{"/test/", "/test/foo"},
+		},
+		{
+			in:    "test-with-directory-entry.tar.xz",
+			files: []string{"/test/", "/test/foo"},
+		},
+		{
+			in:    "test-with-directory-entry.tar.bz2",
+			files: []string{"/test/", "/test/foo"},
+		},
+		{
+			in:    "test-with-directory-entry.tar.zst",
+			files: []string{"/test/", "/test/foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+
+			f, err := os.Open(filepath.Join(testdataPath(), tt.in))
+			if err != nil {
+				t.Fatalf("failed to open %q. error=%v", tt.in, err)
+			}
+			t.Cleanup(func() { f.Close() })
+			st, err := f.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ExtractArchive(tmpDir.Root(), f, st.Size()); err != nil {
+				t.Fatalf("failed to extract %q. error=%v", tt.in, err)
+			}
+
+			outFiles := collectFiles(t, tmpDir.Root())
+			if !reflect.DeepEqual(outFiles, tt.files) {
+				t.Fatalf("for %q, expected=%v, got=%v", tt.in, tt.files, outFiles)
+			}
+		})
+	}
+}
+
+func TestBinaryExtractor(t *testing.T) {
+	const content = "#!/bin/sh\necho hello\n"
+	tests := []struct {
+		name    string
+		bin     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "flat",
+			bin:  "kubectl-foo",
+			want: "kubectl-foo",
+		},
+		{
+			name: "nested",
+			bin:  "bin/kubectl-foo",
+			want: "bin/kubectl-foo",
+		},
+		{
+			name:    "escaping",
+			bin:     "../kubectl-foo",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+			r := strings.NewReader(content)
+			err := BinaryExtractor(tt.bin)(tmpDir.Root(), r, r.Size())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BinaryExtractor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			path := tmpDir.Path(tt.want)
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != content {
+				t.Errorf("BinaryExtractor() wrote %q, want %q", got, content)
+			}
+			if st, err := os.Stat(path); err != nil {
+				t.Fatal(err)
+			} else if runtime.GOOS != "windows" && st.Mode()&0o111 == 0 {
+				t.Errorf("BinaryExtractor() created non-executable file (mode=%s)", st.Mode())
+			}
+		})
+	}
+}
+
 // collectFiles lists the files by walking the path. It prefixes elements with
 // "/" and appends "/" to directories.
 func collectFiles(t *testing.T, scanPath string) []string {
@@ -210,6 +328,89 @@ func TestDownloader_Get(t *testing.T) {
 	}
 }
 
+var _ Fetcher = uriFetcher{}
+
+// uriFetcher serves the local file mapped to the requested uri, and fails for
+// any other uri.
+type uriFetcher map[string]string
+
+func (f uriFetcher) Get(uri string) (io.ReadCloser, error) {
+	path, ok := f[uri]
+	if !ok {
+		return nil, errors.Errorf("test fail for %s", uri)
+	}
+	return os.Open(path)
+}
+
+func TestGetFirst(t *testing.T) {
+	archive := filepath.Join(testdataPath(), "test-flat-hierarchy.tar.gz")
+	const checksum = "433b9e0b6cb9f064548f451150799daadcc70a3496953490c5148c8e550d2f4e"
+	const wrongChecksum = "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+
+	tests := []struct {
+		name     string
+		uris     []string
+		fetcher  Fetcher
+		checksum string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "first uri works",
+			uris:     []string{"a", "b"},
+			fetcher:  uriFetcher{"a": archive, "b": archive},
+			checksum: checksum,
+			want:     "a",
+		},
+		{
+			name:     "falls back to mirror",
+			uris:     []string{"a", "b", "c"},
+			fetcher:  uriFetcher{"c": archive},
+			checksum: checksum,
+			want:     "c",
+		},
+		{
+			name:     "checksum mismatch on all",
+			uris:     []string{"a", "b"},
+			fetcher:  uriFetcher{"a": archive, "b": archive},
+			checksum: wrongChecksum,
+			wantErr:  true,
+		},
+		{
+			name:     "no uris",
+			fetcher:  uriFetcher{},
+			checksum: checksum,
+			wantErr:  true,
+		},
+		{
+			name:     "malformed checksum",
+			uris:     []string{"a"},
+			fetcher:  uriFetcher{"a": archive},
+			checksum: "not-a-checksum",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+			newVerifier := func() (Verifier, error) { return NewSha256Verifier(tt.checksum) }
+			got, err := GetFirst(tt.uris, tmpDir.Root(), newVerifier, tt.fetcher, ExtractArchive)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetFirst() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetFirst() = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if files := collectFiles(t, tmpDir.Root()); len(files) == 0 {
+				t.Errorf("GetFirst() did not extract any files")
+			}
+		})
+	}
+}
+
 func Test_download(t *testing.T) {
 	filePath := filepath.Join(testdataPath(), "test-with-directory-entry.zip")
 	downloadOriginal, err := os.ReadFile(filePath)
@@ -325,6 +526,38 @@ func Test_detectMIMEType(t *testing.T) {
 			want:    "application/x-gzip",
 			wantErr: false,
 		},
+		{
+			name: "type tar.xz",
+			args: args{
+				file: filepath.Join(testdataPath(), "test-with-directory-entry.tar.xz"),
+			},
+			want:    "application/x-xz",
+			wantErr: false,
+		},
+		{
+			name: "type tar.bz2",
+			args: args{
+				file: filepath.Join(testdataPath(), "test-with-directory-entry.tar.bz2"),
+			},
+			want:    "application/x-bzip2",
+			wantErr: false,
+		},
+		{
+			name: "type tar.zst",
+			args: args{
+				file: filepath.Join(testdataPath(), "test-with-directory-entry.tar.zst"),
+			},
+			want:    "application/zstd",
+			wantErr: false,
+		},
+		{
+			name: "type tar",
+			args: args{
+				file: filepath.Join(testdataPath(), "test-with-directory-entry.tar"),
+			},
+			want:    "application/x-tar",
+			wantErr: false,
+		},
 		{
 			name: "type bash-utf8",
 			args: args{
@@ -416,7 +649,7 @@ func Test_extractArchive(t *testing.T) {
 	defer func() {
 		defaultExtractors = oldextractors
 	}()
-	defaultExtractors = map[string]extractor{
+	defaultExtractors = map[string]Extractor{
 		"application/octet-stream": func(_ string, _ io.ReaderAt, _ int64) error { return nil },
 		"text/plain":               func(_ string, _ io.ReaderAt, _ int64) error { return errors.New("fail test") },
 	}
@@ -462,8 +695,8 @@ func Test_extractArchive(t *testing.T) {
 				return
 			}
 
-			if err := extractArchive(tt.args.dst, fd, st.Size()); (err != nil) != tt.wantErr {
-				t.Errorf("extractArchive() error = %v, wantErr %v", err, tt.wantErr)
+			if err := ExtractArchive(tt.args.dst, fd, st.Size()); (err != nil) != tt.wantErr {
+				t.Errorf("ExtractArchive() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -508,12 +741,53 @@ func Test_suspiciousPath(t *testing.T) {
 			shouldErr: true,
 		},
 		{
-			path:      `a/../foo`,
+			path: `a/../foo`,
+		},
+		{
+			path:      `a/../../foo`,
+			shouldErr: true,
+		},
+		{
+			path:      `a\..\..\foo`,
+			shouldErr: true,
+		},
+		{
+			path:      `..`,
 			shouldErr: true,
 		},
 		{
 			path: `a/././foo`,
 		},
+		{
+			path: `foo..bar.txt`,
+		},
+		{
+			path: `..foo/bar`,
+		},
+		{
+			path:      `C:\foo`,
+			shouldErr: true,
+		},
+		{
+			path:      `C:foo`,
+			shouldErr: true,
+		},
+		{
+			path:      `c:/foo`,
+			shouldErr: true,
+		},
+		{
+			path:      `\\server\share\foo`,
+			shouldErr: true,
+		},
+		{
+			path:      `//server/share/foo`,
+			shouldErr: true,
+		},
+		{
+			path:      "foo\x00.txt",
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -541,8 +815,20 @@ func Test_extractMaliciousArchive(t *testing.T) {
 			path: "/foo",
 		},
 		{
-			name: "contains ..",
-			path: "a/../foo",
+			name: "escapes with ..",
+			path: "a/../../foo",
+		},
+		{
+			name: "escapes with backslashes",
+			path: `..\foo`,
+		},
+		{
+			name: "drive letter",
+			path: `C:\foo`,
+		},
+		{
+			name: "UNC path",
+			path: `\\server\share\foo`,
 		},
 	}
 
@@ -585,6 +871,187 @@ func Test_extractMaliciousArchive(t *testing.T) {
 	}
 }
 
+func Test_extractTARGZ_links(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires extra privileges on windows")
+	}
+	dir := func(name string) *tar.Header {
+		return &tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0o755}
+	}
+	file := func(name string) *tar.Header {
+		return &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o755}
+	}
+	symlink := func(name, target string) *tar.Header {
+		return &tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target}
+	}
+	hardlink := func(name, target string) *tar.Header {
+		return &tar.Header{Typeflag: tar.TypeLink, Name: name, Linkname: target}
+	}
+
+	tests := []struct {
+		name    string
+		entries []*tar.Header
+		wantErr bool
+	}{
+		{
+			name: "symlink to sibling directory",
+			entries: []*tar.Header{
+				file("libexec/tool"),
+				dir("bin/"),
+				symlink("bin/tool", "../libexec/tool"),
+			},
+		},
+		{
+			name: "symlink created before its target",
+			entries: []*tar.Header{
+				symlink("bin/tool", "../libexec/tool"),
+				file("libexec/tool"),
+			},
+		},
+		{
+			name:    "dangling symlink within archive",
+			entries: []*tar.Header{symlink("tool", "missing/tool")},
+		},
+		{
+			name: "hard link",
+			entries: []*tar.Header{
+				file("libexec/tool"),
+				hardlink("bin/tool", "libexec/tool"),
+			},
+		},
+		{
+			name:    "symlink escaping archive",
+			entries: []*tar.Header{symlink("bin/tool", "../../tool")},
+			wantErr: true,
+		},
+		{
+			name:    "symlink to absolute path",
+			entries: []*tar.Header{symlink("tool", "/etc/passwd")},
+			wantErr: true,
+		},
+		{
+			name: "symlink escaping through another symlink",
+			entries: []*tar.Header{
+				symlink("a", "."),
+				symlink("a/b", "../tool"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "file written through symlink",
+			entries: []*tar.Header{
+				symlink("a", "."),
+				symlink("b", "a/../tool"),
+				file("b/evil"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "hard link escaping archive",
+			entries: []*tar.Header{hardlink("tool", "../tool")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// extract to a subdirectory, so that escaping links have a
+			// location to point to within the temp dir
+			tmpDir := testutil.NewTempDir(t)
+			targetDir := tmpDir.Path("target")
+			if err := os.Mkdir(targetDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := tarGZArchiveOfEntriesForTesting(tt.entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = extractTARGZ(targetDir, reader, reader.Size())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractTARGZ() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Lstat(tmpDir.Path("tool")); !os.IsNotExist(err) {
+				t.Errorf("extractTARGZ() created a file outside of the target directory")
+			}
+		})
+	}
+}
+
+// maliciousEntries is the seed corpus of archive entry names used to fuzz
+// archive extraction.
+var maliciousEntries = []string{
+	"../foo",
+	"a/../../foo",
+	"./../foo",
+	`..\foo`,
+	`a\..\..\foo`,
+	"/foo",
+	`\foo`,
+	"//server/share/foo",
+	`\\server\share\foo`,
+	`C:\foo`,
+	"C:foo",
+	"c:/foo",
+	"..",
+	"a/b/../../../foo",
+}
+
+func FuzzExtractArchive(f *testing.F) {
+	for _, name := range maliciousEntries {
+		for _, archive := range []func(map[string]string) (*bytes.Reader, error){tarGZArchiveForTesting, zipArchiveReaderForTesting} {
+			r, err := archive(map[string]string{name: "malicious content"})
+			if err != nil {
+				f.Fatal(err)
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(b)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tmpDir := testutil.NewTempDir(t)
+		targetDir := tmpDir.Path("target")
+		if err := os.Mkdir(targetDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		// the archive may be invalid, but nothing may be written outside of the target dir
+		_ = ExtractArchive(targetDir, bytes.NewReader(data), int64(len(data)))
+
+		entries, err := os.ReadDir(tmpDir.Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if e.Name() != "target" {
+				t.Fatalf("archive extraction created %q outside of the target directory", e.Name())
+			}
+		}
+	})
+}
+
+func FuzzSuspiciousPath(f *testing.F) {
+	for _, name := range maliciousEntries {
+		f.Add(name)
+	}
+	f.Add("foo..bar.txt")
+	f.Add("a/../foo")
+
+	f.Fuzz(func(t *testing.T, name string) {
+		if suspiciousPath(name) != nil {
+			return
+		}
+		root := filepath.Join(string(filepath.Separator)+"root", "target")
+		if _, ok := pathutil.IsSubPath(root, filepath.Join(root, filepath.FromSlash(name))); !ok {
+			t.Fatalf("suspiciousPath(%q) accepted an entry outside of the target directory", name)
+		}
+	})
+}
+
 // tarGZArchiveForTesting creates an in-memory zip archive with entries from
 // the files map, where keys are the paths and values are the contents.
 // For example, to create an empty file `a` and another file `b/c`:
@@ -647,3 +1114,53 @@ func zipArchiveReaderForTesting(files map[string]string) (*bytes.Reader, error)
 	}
 	return bytes.NewReader(archiveBuffer.Bytes()), nil
 }
+
+// tarGZArchiveOfEntriesForTesting creates an in-memory tar.gz archive with the
+// given entries. Regular files have their name as content.
+func tarGZArchiveOfEntriesForTesting(entries []*tar.Header) (*bytes.Reader, error) {
+	archiveBuffer := &bytes.Buffer{}
+	gzArchiveBuffer := gzip.NewWriter(archiveBuffer)
+	tw := tar.NewWriter(gzArchiveBuffer)
+	for _, hdr := range entries {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(hdr.Name))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(hdr.Name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzArchiveBuffer.Close(); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(archiveBuffer.Bytes()), nil
+}
+
+func Test_extractZIP_staysInTargetDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires extra privileges on windows")
+	}
+	tmpDir := testutil.NewTempDir(t)
+	outside := testutil.NewTempDir(t)
+	if err := os.Symlink(outside.Root(), tmpDir.Path("link")); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zipArchiveReaderForTesting(map[string]string{"link/foo": "content"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := extractZIP(tmpDir.Root(), reader, reader.Size()); err == nil {
+		t.Error("expected extractZIP to fail writing through a link out of the target directory")
+	}
+	if _, err := os.Stat(outside.Path("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written outside of the target directory, got err=%v", err)
+	}
+}
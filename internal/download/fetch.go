@@ -35,6 +35,9 @@ var _ Fetcher = HTTPFetcher{}
 type HTTPFetcher struct {
 	EnableNetrc bool
 	NetrcFile   string
+	// CheckRedirect, if set, is called with the uri of each redirect, which is
+	// not followed if it returns an error.
+	CheckRedirect func(uri string) error
 }
 
 // Get gets the file and returns an stream to read the file.
@@ -58,7 +61,7 @@ func (f HTTPFetcher) Get(uri string) (io.ReadCloser, error) {
 		}
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := NewHTTPClient(f.CheckRedirect).Do(req)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to download %q", uri)
 	}
@@ -69,6 +72,25 @@ func (f HTTPFetcher) Get(uri string) (io.ReadCloser, error) {
 	return resp.Body, nil
 }
 
+// maxRedirects is the number of redirects followed, as by http.DefaultClient.
+const maxRedirects = 10
+
+// NewHTTPClient returns the client that files are downloaded with. If
+// checkRedirect is not nil, it is called with the uri of each redirect, which
+// is not followed if it returns an error.
+func NewHTTPClient(checkRedirect func(uri string) error) *http.Client {
+	return &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if checkRedirect == nil {
+			return nil
+		}
+		klog.V(3).Infof("Following redirect to %q", req.URL)
+		return errors.Wrap(checkRedirect(req.URL.String()), "refusing to follow redirect")
+	}}
+}
+
 var _ Fetcher = fileFetcher{}
 
 type fileFetcher struct{ f string }
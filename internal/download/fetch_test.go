@@ -19,10 +19,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 func TestHTTPFetcher_Get(t *testing.T) {
@@ -127,3 +130,34 @@ func TestHTTPFetcher_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPFetcher_Get_checksRedirects(t *testing.T) {
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("foo"))
+	}))
+	defer allowed.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("redirect to other host %v was followed", r.URL)
+	}))
+	defer other.Close()
+
+	h := HTTPFetcher{CheckRedirect: func(uri string) error {
+		if !strings.HasPrefix(uri, allowed.URL+"/") {
+			return errors.Errorf("uri %q is not allowed", uri)
+		}
+		return nil
+	}}
+	got, err := h.Get(allowed.URL + "/redirect?to=" + url.QueryEscape(allowed.URL+"/foo"))
+	if err != nil {
+		t.Fatalf("expected redirect to the same host to be followed: %v", err)
+	}
+	got.Close()
+
+	if _, err := h.Get(allowed.URL + "/redirect?to=" + url.QueryEscape(other.URL+"/foo")); err == nil {
+		t.Error("expected error following redirect to other host")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+// limits bounds the resources an archive may consume when it is extracted.
+// A zero value disables the corresponding limit.
+type limits struct {
+	maxTotalSize int64
+	maxFileSize  int64
+	maxEntries   int64
+}
+
+// defaultLimits are generous enough for any reasonable plugin while still
+// stopping decompression bombs before they fill up the disk.
+var defaultLimits = limits{
+	maxTotalSize: 1 << 30,
+	maxFileSize:  512 << 20,
+	maxEntries:   10000,
+}
+
+// limitsFromEnv returns the default extraction limits, overridden by the
+// KREW_MAX_EXTRACT_SIZE, KREW_MAX_EXTRACT_FILE_SIZE and
+// KREW_MAX_EXTRACT_ENTRIES environment variables.
+func limitsFromEnv() (limits, error) {
+	l := defaultLimits
+	var err error
+	if l.maxTotalSize, err = SizeFromEnv("KREW_MAX_EXTRACT_SIZE", l.maxTotalSize); err != nil {
+		return l, err
+	}
+	if l.maxFileSize, err = SizeFromEnv("KREW_MAX_EXTRACT_FILE_SIZE", l.maxFileSize); err != nil {
+		return l, err
+	}
+	if v := os.Getenv("KREW_MAX_EXTRACT_ENTRIES"); v != "" {
+		if l.maxEntries, err = strconv.ParseInt(v, 10, 64); err != nil || l.maxEntries < 0 {
+			return l, errors.Errorf("invalid KREW_MAX_EXTRACT_ENTRIES value %q, must be a non-negative integer", v)
+		}
+	}
+	klog.V(4).Infof("Extraction limits: total size=%d, file size=%d, entries=%d", l.maxTotalSize, l.maxFileSize, l.maxEntries)
+	return l, nil
+}
+
+// MaxExtractSize returns the limit of the total size of the files extracted
+// from an archive, or 0 if the size is not limited.
+func MaxExtractSize() (int64, error) {
+	l, err := limitsFromEnv()
+	return l.maxTotalSize, err
+}
+
+// SizeFromEnv parses the size in the given environment variable, which can be
+// a plain number of bytes or a quantity like "500Mi" or "2G". The default is
+// returned if the variable is not set.
+func SizeFromEnv(name string, def int64) (int64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	q, err := resource.ParseQuantity(v)
+	if err != nil || q.Sign() < 0 {
+		return 0, errors.Errorf("invalid %s value %q, must be a non-negative size like 500Mi", name, v)
+	}
+	return q.Value(), nil
+}
+
+// extractBudget keeps track of the resources consumed while extracting a
+// single archive.
+type extractBudget struct {
+	limits
+	totalSize int64
+	entries   int64
+}
+
+func newExtractBudget() (*extractBudget, error) {
+	l, err := limitsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &extractBudget{limits: l}, nil
+}
+
+// entry records that one more entry is being extracted.
+func (b *extractBudget) entry() error {
+	b.entries++
+	if b.maxEntries > 0 && b.entries > b.maxEntries {
+		return errors.Errorf("refusing to unpack archive with more than %d entries (limit set by KREW_MAX_EXTRACT_ENTRIES)", b.maxEntries)
+	}
+	return nil
+}
+
+// copy copies the content of the entry name from src to dst, and fails as
+// soon as the entry or the archive as a whole exceeds the size limits.
+func (b *extractBudget) copy(dst io.Writer, src io.Reader, name string) error {
+	n, err := io.Copy(dst, io.LimitReader(src, b.remaining()))
+	b.totalSize += n
+	if b.maxFileSize > 0 && n > b.maxFileSize {
+		return errors.Errorf("refusing to unpack %q larger than %d bytes (limit set by KREW_MAX_EXTRACT_FILE_SIZE)", name, b.maxFileSize)
+	}
+	if b.maxTotalSize > 0 && b.totalSize > b.maxTotalSize {
+		return errors.Errorf("refusing to unpack archive larger than %d bytes (limit set by KREW_MAX_EXTRACT_SIZE)", b.maxTotalSize)
+	}
+	return err
+}
+
+// remaining returns how many bytes the next entry may be read before a limit
+// is known to be exceeded.
+func (b *extractBudget) remaining() int64 {
+	n := int64(math.MaxInt64)
+	if b.maxFileSize > 0 {
+		n = b.maxFileSize + 1
+	}
+	if b.maxTotalSize > 0 && b.maxTotalSize-b.totalSize+1 < n {
+		n = b.maxTotalSize - b.totalSize + 1
+	}
+	return n
+}
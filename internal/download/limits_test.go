@@ -0,0 +1,126 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExtractArchive_limits(t *testing.T) {
+	big := strings.Repeat("0", 64<<10)
+	tests := []struct {
+		name    string
+		env     map[string]string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:  "within default limits",
+			files: map[string]string{"a": big, "b": big},
+		},
+		{
+			name:    "file too large",
+			env:     map[string]string{"KREW_MAX_EXTRACT_FILE_SIZE": "16Ki"},
+			files:   map[string]string{"a": big},
+			wantErr: "KREW_MAX_EXTRACT_FILE_SIZE",
+		},
+		{
+			name:  "file exactly at limit",
+			env:   map[string]string{"KREW_MAX_EXTRACT_FILE_SIZE": "64Ki"},
+			files: map[string]string{"a": big},
+		},
+		{
+			name:    "archive too large",
+			env:     map[string]string{"KREW_MAX_EXTRACT_SIZE": "100Ki"},
+			files:   map[string]string{"a": big, "b": big},
+			wantErr: "KREW_MAX_EXTRACT_SIZE",
+		},
+		{
+			name:  "size limits disabled",
+			env:   map[string]string{"KREW_MAX_EXTRACT_SIZE": "0", "KREW_MAX_EXTRACT_FILE_SIZE": "0"},
+			files: map[string]string{"a": big, "b": big},
+		},
+		{
+			name:    "too many entries",
+			env:     map[string]string{"KREW_MAX_EXTRACT_ENTRIES": "2"},
+			files:   map[string]string{"a": "a", "b": "b", "c": "c"},
+			wantErr: "KREW_MAX_EXTRACT_ENTRIES",
+		},
+		{
+			name:    "invalid size",
+			env:     map[string]string{"KREW_MAX_EXTRACT_SIZE": "lots"},
+			files:   map[string]string{"a": "a"},
+			wantErr: "invalid KREW_MAX_EXTRACT_SIZE",
+		},
+		{
+			name:    "negative entries",
+			env:     map[string]string{"KREW_MAX_EXTRACT_ENTRIES": "-1"},
+			files:   map[string]string{"a": "a"},
+			wantErr: "invalid KREW_MAX_EXTRACT_ENTRIES",
+		},
+	}
+	archivers := map[string]func(map[string]string) (*bytes.Reader, error){
+		"tar.gz": tarGZArchiveForTesting,
+		"zip":    zipArchiveReaderForTesting,
+	}
+	for _, tt := range tests {
+		for format, archiver := range archivers {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				for k, v := range tt.env {
+					t.Setenv(k, v)
+				}
+				archive, err := archiver(tt.files)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = ExtractArchive(t.TempDir(), archive, archive.Size())
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Fatalf("ExtractArchive() unexpected error: %v", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExtractArchive() error = %v, want error containing %q", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestBinaryExtractor_limits(t *testing.T) {
+	t.Setenv("KREW_MAX_EXTRACT_FILE_SIZE", "1Ki")
+	content := bytes.NewReader(make([]byte, 2<<10))
+	err := BinaryExtractor("foo")(t.TempDir(), content, content.Size())
+	if err == nil || !strings.Contains(err.Error(), "KREW_MAX_EXTRACT_FILE_SIZE") {
+		t.Fatalf("BinaryExtractor() error = %v, want file size limit error", err)
+	}
+}
+
+func Test_extractBudget_copy(t *testing.T) {
+	b := &extractBudget{limits: limits{maxTotalSize: 10, maxFileSize: 6}}
+	var out bytes.Buffer
+	if err := b.copy(&out, strings.NewReader("123456"), "a"); err != nil {
+		t.Fatalf("copy() within limits failed: %v", err)
+	}
+	if err := b.copy(&out, strings.NewReader("12345"), "b"); err == nil {
+		t.Fatal("copy() expected total size limit error")
+	}
+	if got := out.Len(); got > 11 {
+		t.Fatalf("copy() read %d bytes, expected reading to stop right after the limit", got)
+	}
+}
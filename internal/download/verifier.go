@@ -16,7 +16,9 @@ package download
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"hash"
 	"io"
@@ -31,26 +33,103 @@ type Verifier interface {
 	Verify() error
 }
 
-var _ Verifier = sha256Verifier{}
+var _ Verifier = hashVerifier{}
 
-type sha256Verifier struct {
+type hashVerifier struct {
 	hash.Hash
+	name       string
 	wantedHash []byte
 }
 
-// NewSha256Verifier creates a Verifier that tests against the given hash.
-func NewSha256Verifier(hashed string) Verifier {
-	raw, _ := hex.DecodeString(hashed)
-	return sha256Verifier{
-		Hash:       sha256.New(),
-		wantedHash: raw,
+// NewSha256Verifier creates a Verifier that tests against the given
+// hex-encoded sha256 sum. It fails if the sum is malformed.
+func NewSha256Verifier(hashed string) (Verifier, error) {
+	return newHashVerifier("sha256", sha256.New(), hashed)
+}
+
+// NewSha512Verifier creates a Verifier that tests against the given
+// hex-encoded sha512 sum. It fails if the sum is malformed.
+func NewSha512Verifier(hashed string) (Verifier, error) {
+	return newHashVerifier("sha512", sha512.New(), hashed)
+}
+
+func newHashVerifier(name string, h hash.Hash, hashed string) (Verifier, error) {
+	raw, err := hex.DecodeString(hashed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s sum %q", name, hashed)
+	}
+	if len(raw) != h.Size() {
+		return nil, errors.Errorf("invalid %s sum %q, must be %d hex characters", name, hashed, 2*h.Size())
 	}
+	return hashVerifier{
+		Hash:       h,
+		name:       name,
+		wantedHash: raw,
+	}, nil
 }
 
-func (v sha256Verifier) Verify() error {
-	klog.V(1).Infof("Compare sha256 (%s) signed version", hex.EncodeToString(v.wantedHash))
+func (v hashVerifier) Verify() error {
+	klog.V(1).Infof("Compare %s (%s) signed version", v.name, hex.EncodeToString(v.wantedHash))
 	if bytes.Equal(v.wantedHash, v.Sum(nil)) {
 		return nil
 	}
-	return errors.Errorf("checksum does not match, want: %x, got %x", v.wantedHash, v.Sum(nil))
+	return errors.Errorf("%s checksum does not match, want: %x, got %x", v.name, v.wantedHash, v.Sum(nil))
+}
+
+var _ Verifier = &ed25519Verifier{}
+
+type ed25519Verifier struct {
+	bytes.Buffer
+	publicKey ed25519.PublicKey
+	signature []byte
+}
+
+// NewEd25519Verifier creates a Verifier that checks the ed25519 signature of
+// the content written to it.
+func NewEd25519Verifier(publicKey ed25519.PublicKey, signature []byte) Verifier {
+	return &ed25519Verifier{
+		publicKey: publicKey,
+		signature: signature,
+	}
+}
+
+func (v *ed25519Verifier) Verify() error {
+	klog.V(1).Infof("Verifying ed25519 signature with public key %x", []byte(v.publicKey))
+	if len(v.publicKey) != ed25519.PublicKeySize {
+		return errors.Errorf("invalid ed25519 public key size %d", len(v.publicKey))
+	}
+	if ed25519.Verify(v.publicKey, v.Bytes(), v.signature) {
+		return nil
+	}
+	return errors.New("signature does not match")
+}
+
+var _ Verifier = chainVerifier{}
+
+type chainVerifier struct {
+	io.Writer
+	verifiers []Verifier
+}
+
+// NewChainVerifier creates a Verifier that passes the content written to it to
+// all verifiers, and succeeds if all of them succeed. It is used to check
+// several digests and signatures of the same file.
+func NewChainVerifier(verifiers ...Verifier) Verifier {
+	writers := make([]io.Writer, len(verifiers))
+	for i, v := range verifiers {
+		writers[i] = v
+	}
+	return chainVerifier{
+		Writer:    io.MultiWriter(writers...),
+		verifiers: verifiers,
+	}
+}
+
+func (v chainVerifier) Verify() error {
+	for _, verifier := range v.verifiers {
+		if err := verifier.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -16,6 +16,7 @@ package download
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"io"
 	"testing"
 )
@@ -38,6 +39,14 @@ func TestSha256Verifier(t *testing.T) {
 			write:     []byte("hello world"),
 			wantError: false,
 		},
+		{
+			name: "test okay uppercase hash",
+			args: args{
+				hash: "B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9",
+			},
+			write:     []byte("hello world"),
+			wantError: false,
+		},
 		{
 			name: "test wrong hash",
 			args: args{
@@ -49,7 +58,10 @@ func TestSha256Verifier(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			v := NewSha256Verifier(tt.args.hash)
+			v, err := NewSha256Verifier(tt.args.hash)
+			if err != nil {
+				t.Fatalf("NewSha256Verifier(%q) unexpected error: %v", tt.args.hash, err)
+			}
 			_, _ = io.Copy(v, bytes.NewReader(tt.write))
 			if err := v.Verify(); (err != nil) != tt.wantError {
 				t.Errorf("NewSha256Verifier().Write(%x).Verify() = %v, wantReader %v", tt.write, err, tt.wantError)
@@ -58,3 +70,124 @@ func TestSha256Verifier(t *testing.T) {
 		})
 	}
 }
+
+func TestSha512Verifier(t *testing.T) {
+	const hash = "309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f"
+	v, err := NewSha512Verifier(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(v, bytes.NewReader([]byte("hello world")))
+	if err := v.Verify(); err != nil {
+		t.Errorf("Verify() of matching content failed: %v", err)
+	}
+
+	v, err = NewSha512Verifier(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(v, bytes.NewReader([]byte("HELLO WORLD")))
+	if err := v.Verify(); err == nil {
+		t.Error("Verify() of different content succeeded")
+	}
+}
+
+func TestHashVerifier_malformed(t *testing.T) {
+	const sha256Hash = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	tests := []struct {
+		name        string
+		newVerifier func(string) (Verifier, error)
+		hash        string
+	}{
+		{name: "empty sha256", newVerifier: NewSha256Verifier, hash: ""},
+		{name: "non-hex sha256", newVerifier: NewSha256Verifier, hash: "z" + sha256Hash[1:]},
+		{name: "odd length sha256", newVerifier: NewSha256Verifier, hash: sha256Hash[1:]},
+		{name: "truncated sha256", newVerifier: NewSha256Verifier, hash: sha256Hash[2:]},
+		{name: "sha256 given as sha512", newVerifier: NewSha512Verifier, hash: sha256Hash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.newVerifier(tt.hash); err == nil {
+				t.Errorf("expected error for malformed hash %q", tt.hash)
+			}
+		})
+	}
+}
+
+func TestEd25519Verifier(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	otherKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+	signature := ed25519.Sign(key, []byte("hello world"))
+
+	tests := []struct {
+		name      string
+		publicKey ed25519.PublicKey
+		write     []byte
+		wantError bool
+	}{
+		{
+			name:      "valid signature",
+			publicKey: key.Public().(ed25519.PublicKey),
+			write:     []byte("hello world"),
+		},
+		{
+			name:      "modified content",
+			publicKey: key.Public().(ed25519.PublicKey),
+			write:     []byte("HELLO WORLD"),
+			wantError: true,
+		},
+		{
+			name:      "signed by another key",
+			publicKey: otherKey.Public().(ed25519.PublicKey),
+			write:     []byte("hello world"),
+			wantError: true,
+		},
+		{
+			name:      "malformed key",
+			publicKey: ed25519.PublicKey("short"),
+			write:     []byte("hello world"),
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewEd25519Verifier(tt.publicKey, signature)
+			_, _ = io.Copy(v, bytes.NewReader(tt.write))
+			if err := v.Verify(); (err != nil) != tt.wantError {
+				t.Errorf("NewEd25519Verifier().Write(%q).Verify() = %v, wantError %v", tt.write, err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestChainVerifier(t *testing.T) {
+	const okHash = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	const wrongHash = "0000000000000000000000000000000000000000000000000000000000000000"
+	tests := []struct {
+		name      string
+		hashes    []string
+		wantError bool
+	}{
+		{name: "no verifiers"},
+		{name: "all pass", hashes: []string{okHash, okHash}},
+		{name: "first fails", hashes: []string{wrongHash, okHash}, wantError: true},
+		{name: "last fails", hashes: []string{okHash, wrongHash}, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verifiers []Verifier
+			for _, h := range tt.hashes {
+				v, err := NewSha256Verifier(h)
+				if err != nil {
+					t.Fatal(err)
+				}
+				verifiers = append(verifiers, v)
+			}
+			v := NewChainVerifier(verifiers...)
+			_, _ = io.Copy(v, bytes.NewReader([]byte("hello world")))
+			if err := v.Verify(); (err != nil) != tt.wantError {
+				t.Errorf("NewChainVerifier().Verify() = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
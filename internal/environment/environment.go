@@ -72,11 +72,57 @@ func (p Paths) IndexPluginsPath(name string) string {
 	return filepath.Join(p.IndexPath(name), "plugins")
 }
 
+// IndexConfigPath returns the file where the local settings of a plugin index
+// are stored.
+//
+// e.g. {BasePath}/config/index/{name}.yaml
+func (p Paths) IndexConfigPath(name string) string {
+	return filepath.Join(p.base, "config", "index", name+constants.ManifestExtension)
+}
+
+// IndexTrustPath returns the file where the last verified state of the signed
+// metadata of a plugin index is stored.
+//
+// e.g. {BasePath}/config/index/{name}.trust.json
+func (p Paths) IndexTrustPath(name string) string {
+	return filepath.Join(p.base, "config", "index", name+".trust.json")
+}
+
+// IndexStatePath returns the file where the state of the local copy of a
+// plugin index, such as the time of its last update, is stored.
+//
+// e.g. {BasePath}/config/index/{name}.state.json
+func (p Paths) IndexStatePath(name string) string {
+	return filepath.Join(p.base, "config", "index", name+".state.json")
+}
+
+// IndexCatalogPath returns the file where the parsed plugin manifests of a
+// plugin index are cached.
+//
+// e.g. {BasePath}/cache/index/{name}.catalog.json
+func (p Paths) IndexCatalogPath(name string) string {
+	return filepath.Join(p.base, "cache", "index", name+".catalog.json")
+}
+
+// AuditLogPath returns the file where plugin and index operations are
+// logged, one JSON object per line.
+//
+// e.g. {BasePath}/audit.jsonl
+func (p Paths) AuditLogPath() string { return filepath.Join(p.base, "audit.jsonl") }
+
 // InstallReceiptsPath returns the base directory where plugin receipts are stored.
 //
 // e.g. {BasePath}/receipts
 func (p Paths) InstallReceiptsPath() string { return filepath.Join(p.base, "receipts") }
 
+// ReceiptsUpgradedPath returns the file that marks that the receipts stored
+// by older versions of krew were upgraded.
+//
+// e.g. {BasePath}/receipts/.upgraded
+func (p Paths) ReceiptsUpgradedPath() string {
+	return filepath.Join(p.InstallReceiptsPath(), ".upgraded")
+}
+
 // BinPath returns the path where plugin executable symbolic links are found.
 // This path should be added to $PATH in client machine.
 //
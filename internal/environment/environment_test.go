@@ -59,6 +59,16 @@ func TestPaths(t *testing.T) {
 	if got, expected := p.IndexPluginsPath(constants.DefaultIndexName), filepath.FromSlash("/foo/index/default/plugins"); got != expected {
 		t.Errorf("IndexPluginsPath(\"%s\")=%s; expected=%s", constants.DefaultIndexName, got, expected)
 	}
+	if got, expected := p.IndexConfigPath(constants.DefaultIndexName), filepath.FromSlash("/foo/config/index/default.yaml"); got != expected {
+		t.Errorf("IndexConfigPath(\"%s\")=%s; expected=%s", constants.DefaultIndexName, got, expected)
+	}
+	if got, expected := p.IndexTrustPath(constants.DefaultIndexName), filepath.FromSlash("/foo/config/index/default.trust.json"); got != expected {
+		t.Errorf("IndexTrustPath(\"%s\")=%s; expected=%s", constants.DefaultIndexName, got, expected)
+	}
+
+	if got, expected := p.IndexCatalogPath(constants.DefaultIndexName), filepath.FromSlash("/foo/cache/index/default.catalog.json"); got != expected {
+		t.Errorf("IndexCatalogPath(\"%s\")=%s; expected=%s", constants.DefaultIndexName, got, expected)
+	}
 
 	if got, expected := p.InstallPath(), filepath.FromSlash("/foo/store"); got != expected {
 		t.Errorf("InstallPath()=%s; expected=%s", got, expected)
@@ -69,9 +79,15 @@ func TestPaths(t *testing.T) {
 	if got, expected := p.PluginVersionInstallPath("my-plugin", "v1"), filepath.FromSlash("/foo/store/my-plugin/v1"); got != expected {
 		t.Errorf("PluginVersionInstallPath()=%s; expected=%s", got, expected)
 	}
+	if got, expected := p.AuditLogPath(), filepath.FromSlash("/foo/audit.jsonl"); got != expected {
+		t.Errorf("AuditLogPath()=%s; expected=%s", got, expected)
+	}
 	if got := p.InstallReceiptsPath(); !strings.HasSuffix(got, filepath.FromSlash("receipts")) {
 		t.Errorf("InstallReceiptsPath()=%s; expected suffix 'receipts'", got)
 	}
+	if got, expected := p.ReceiptsUpgradedPath(), filepath.FromSlash("/foo/receipts/.upgraded"); got != expected {
+		t.Errorf("ReceiptsUpgradedPath()=%s; expected=%s", got, expected)
+	}
 	if got := p.PluginInstallReceiptPath("my-plugin"); !strings.HasSuffix(got, filepath.FromSlash("receipts/my-plugin.yaml")) {
 		t.Errorf("PluginInstallReceiptPath()=%s; expected suffix 'receipts/my-plugin.yaml'", got)
 	}
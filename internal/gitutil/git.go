@@ -16,10 +16,12 @@ package gitutil
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
 	osexec "os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 
@@ -27,17 +29,87 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// EnvGit is the environment variable that selects the git implementation
+// used for indexes. Set it to "system" to run the git binary instead of the
+// built-in implementation.
+const EnvGit = "KREW_GIT"
+
+// checkoutDirs are the directories of index repositories that krew reads,
+// which are the only ones checked out in new clones besides the files at the
+// top level of the repository.
+var checkoutDirs = []string{"plugins", "metadata"}
+
+// commitIDPattern matches refs that can be full or abbreviated commit IDs.
+var commitIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// isCommitOf reports whether ref is the commit ID, or an abbreviation of it,
+// rather than the name of a branch or tag that points to it.
+func isCommitOf(ref, commit string) bool {
+	return commitIDPattern.MatchString(ref) && strings.HasPrefix(commit, strings.ToLower(ref))
+}
+
+// useSystemGit reports whether the git binary should be used.
+func useSystemGit() bool {
+	switch v := os.Getenv(EnvGit); v {
+	case "system":
+		return true
+	case "", "native":
+		return false
+	default:
+		klog.Warningf("Ignoring unknown value %q of %s, expected \"native\" or \"system\"", v, EnvGit)
+		return false
+	}
+}
+
 // EnsureCloned will clone into the destination path, otherwise will return no error.
 func EnsureCloned(uri, destinationPath string) error {
+	return ensureCloned(context.Background(), uri, destinationPath)
+}
+
+func ensureCloned(ctx context.Context, uri, destinationPath string) error {
 	if ok, err := IsGitCloned(destinationPath); err != nil {
 		return err
 	} else if !ok {
-		_, err = Exec("", "clone", "-v", uri, destinationPath)
-		return err
+		if !useSystemGit() {
+			return nativeClone(ctx, uri, destinationPath)
+		}
+		return systemClone(ctx, uri, destinationPath)
 	}
 	return nil
 }
 
+// systemClone clones uri into destinationPath with a sparse checkout of the
+// directories that krew reads. Remote repositories are cloned shallow and
+// without the blobs outside of the sparse checkout, which are only fetched
+// when they are needed.
+func systemClone(ctx context.Context, uri, destinationPath string) error {
+	args := []string{"clone", "-v", "--sparse"}
+	if !isLocal(uri) {
+		args = append(args, "--depth=1", "--filter=blob:none")
+	}
+	if _, err := ExecContext(ctx, "", append(args, uri, destinationPath)...); err != nil {
+		return err
+	}
+	_, err := ExecContext(ctx, destinationPath, append([]string{"sparse-checkout", "set"}, checkoutDirs...)...)
+	return errors.Wrapf(err, "sparse checkout of %q failed", destinationPath)
+}
+
+// fetchArgs returns the arguments of git fetch in the repository at dir, which
+// keep a shallow repository shallow.
+func fetchArgs(dir string, args ...string) []string {
+	fetch := []string{"fetch", "-v"}
+	if isShallow(dir) {
+		fetch = append(fetch, "--depth=1")
+	}
+	return append(fetch, args...)
+}
+
+// isShallow reports whether the repository at dir is a shallow clone.
+func isShallow(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", "shallow"))
+	return err == nil
+}
+
 // IsGitCloned will test if the path is a git dir.
 func IsGitCloned(gitPath string) (bool, error) {
 	f, err := os.Stat(filepath.Join(gitPath, ".git"))
@@ -50,35 +122,105 @@ func IsGitCloned(gitPath string) (bool, error) {
 // update will fetch origin and set HEAD to origin/HEAD
 // and also will create a pristine working directory by removing
 // untracked files and directories.
-func updateAndCleanUntracked(destinationPath string) error {
-	if _, err := Exec(destinationPath, "fetch", "-v"); err != nil {
+func updateAndCleanUntracked(ctx context.Context, destinationPath string) error {
+	if _, err := ExecContext(ctx, destinationPath, fetchArgs(destinationPath)...); err != nil {
 		return errors.Wrapf(err, "fetch index at %q failed", destinationPath)
 	}
 
-	if _, err := Exec(destinationPath, "reset", "--hard", "@{upstream}"); err != nil {
+	if _, err := ExecContext(ctx, destinationPath, "reset", "--hard", "@{upstream}"); err != nil {
 		return errors.Wrapf(err, "reset index at %q failed", destinationPath)
 	}
 
-	_, err := Exec(destinationPath, "clean", "-xfd")
+	_, err := ExecContext(ctx, destinationPath, "clean", "-xfd")
+	return errors.Wrapf(err, "clean index at %q failed", destinationPath)
+}
+
+// updateToRef will fetch the given branch, tag or commit from origin, set HEAD
+// to it and create a pristine working directory.
+func updateToRef(ctx context.Context, destinationPath, ref string) error {
+	target := "FETCH_HEAD"
+	if commit, err := ExecContext(ctx, destinationPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err == nil && isCommitOf(ref, commit) {
+		// Commits don't change, so there is nothing to fetch.
+		klog.V(2).Infof("Commit %q exists locally, not fetching it", ref)
+		target = commit
+	} else if _, err := ExecContext(ctx, destinationPath, fetchArgs(destinationPath, "origin", ref)...); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrapf(err, "fetch index at %q failed", destinationPath)
+		}
+		// Abbreviated commits can't be fetched by name, so fetch everything,
+		// including the history of shallow repositories, and look the ref up
+		// locally.
+		klog.V(2).Infof("Failed to fetch ref %q, fetching all refs instead: %v", ref, err)
+		args := []string{"fetch", "-v", "--tags"}
+		if isShallow(destinationPath) {
+			args = append(args, "--unshallow")
+		}
+		if _, err := ExecContext(ctx, destinationPath, append(args, "origin", "+refs/heads/*:refs/remotes/origin/*")...); err != nil {
+			return errors.Wrapf(err, "fetch index at %q failed", destinationPath)
+		}
+		target = ref + "^{commit}"
+	}
+
+	if _, err := ExecContext(ctx, destinationPath, "reset", "--hard", target); err != nil {
+		return errors.Wrapf(err, "reset index at %q to %q failed", destinationPath, ref)
+	}
+
+	_, err := ExecContext(ctx, destinationPath, "clean", "-xfd")
 	return errors.Wrapf(err, "clean index at %q failed", destinationPath)
 }
 
-// EnsureUpdated will ensure the destination path exists and is up to date.
-func EnsureUpdated(uri, destinationPath string) error {
-	if err := EnsureCloned(uri, destinationPath); err != nil {
+// EnsureUpdated will ensure the destination path exists and is up to date
+// with the given branch, tag or commit, or with the default branch of the
+// remote if ref is empty. Git is stopped when ctx is done.
+func EnsureUpdated(ctx context.Context, uri, destinationPath, ref string) error {
+	if err := ensureCloned(ctx, uri, destinationPath); err != nil {
 		return err
 	}
-	return updateAndCleanUntracked(destinationPath)
+	if !useSystemGit() {
+		return nativeUpdate(ctx, destinationPath, ref)
+	}
+	if ref != "" {
+		return updateToRef(ctx, destinationPath, ref)
+	}
+	return updateAndCleanUntracked(ctx, destinationPath)
+}
+
+// HeadCommit returns the commit checked out in the repository at dir.
+func HeadCommit(dir string) (string, error) {
+	if !useSystemGit() {
+		return nativeHeadCommit(dir)
+	}
+	return Exec(dir, "rev-parse", "HEAD")
+}
+
+// ResetHard checks out the given commit in the repository at dir, discarding
+// any changes.
+func ResetHard(dir, commit string) error {
+	if !useSystemGit() {
+		return errors.Wrapf(nativeResetHard(dir, commit), "reset index at %q failed", dir)
+	}
+	_, err := Exec(dir, "reset", "--hard", commit)
+	return errors.Wrapf(err, "reset index at %q failed", dir)
 }
 
 // GetRemoteURL returns the url of the remote origin
 func GetRemoteURL(dir string) (string, error) {
+	if !useSystemGit() {
+		return nativeRemoteURL(dir)
+	}
 	return Exec(dir, "config", "--get", "remote.origin.url")
 }
 
+// Exec runs the git binary with the given arguments in pwd and returns its
+// output.
 func Exec(pwd string, args ...string) (string, error) {
+	return ExecContext(context.Background(), pwd, args...)
+}
+
+// ExecContext runs git like Exec, and kills it when ctx is done.
+func ExecContext(ctx context.Context, pwd string, args ...string) (string, error) {
 	klog.V(4).Infof("Going to run git %s", strings.Join(args, " "))
-	cmd := osexec.Command("git", args...)
+	cmd := osexec.CommandContext(ctx, "git", args...)
 	cmd.Dir = pwd
 	if runtime.GOOS == "windows" {
 		// Workaround on windows. git for windows can't handle @{uptream} as same as
@@ -94,6 +236,9 @@ func Exec(pwd string, args ...string) (string, error) {
 	}
 	cmd.Stdout, cmd.Stderr = w, w
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", errors.Wrapf(ctx.Err(), "git %s was stopped", args[0])
+		}
 		return "", errors.Wrapf(err, "command execution failure, output=%q", buf.String())
 	}
 	return strings.TrimSpace(buf.String()), nil
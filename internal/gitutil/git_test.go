@@ -0,0 +1,150 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// commit commits a file with the given name and content to the repository at
+// dir with the git binary and returns the commit.
+func commit(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", name},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", content},
+	} {
+		if _, err := Exec(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := Exec(dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestEnsureUpdated(t *testing.T) {
+	for _, impl := range []string{"native", "system"} {
+		t.Run(impl, func(t *testing.T) {
+			t.Setenv(EnvGit, impl)
+			testEnsureUpdated(t)
+		})
+	}
+}
+
+func testEnsureUpdated(t *testing.T) {
+	remote, dest := filepath.Join(t.TempDir(), "remote"), filepath.Join(t.TempDir(), "index")
+	if _, err := Exec("", "init", remote); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, remote, "docs/README.md", "readme")
+	commit(t, remote, "retired.yaml", "[]")
+	first := commit(t, remote, "plugins/foo.yaml", "v1")
+	if _, err := Exec(remote, "tag", "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EnsureCloned(remote, dest); err != nil {
+		t.Fatalf("EnsureCloned() failed: %v", err)
+	}
+	if got, err := GetRemoteURL(dest); err != nil || got != remote {
+		t.Errorf("GetRemoteURL() = %q, %v, expected %q", got, err, remote)
+	}
+	if got, err := HeadCommit(dest); err != nil || got != first {
+		t.Errorf("HeadCommit() = %q, %v, expected %q", got, err, first)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "docs")); !os.IsNotExist(err) {
+		t.Errorf("expected file outside of the plugins directory not to be checked out, got err=%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "retired.yaml")); err != nil {
+		t.Errorf("expected file at the top level to be checked out: %v", err)
+	}
+
+	second := commit(t, remote, "plugins/bar.yaml", "v2")
+	if err := os.MkdirAll(filepath.Join(dest, "untracked"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "plugins", "foo.yaml"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureUpdated(context.Background(), remote, dest, ""); err != nil {
+		t.Fatalf("EnsureUpdated() failed: %v", err)
+	}
+	if got, _ := HeadCommit(dest); got != second {
+		t.Errorf("expected update to the latest commit %s, got %s", second, got)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "untracked")); !os.IsNotExist(err) {
+		t.Errorf("expected untracked directory to be removed, got err=%v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dest, "plugins", "foo.yaml")); err != nil || string(b) != "v1" {
+		t.Errorf("expected changes to tracked file to be discarded, got %q, %v", b, err)
+	}
+
+	if err := EnsureUpdated(context.Background(), remote, dest, "v1"); err != nil {
+		t.Fatalf("EnsureUpdated() to tag failed: %v", err)
+	}
+	if got, _ := HeadCommit(dest); got != first {
+		t.Errorf("expected tag at %s to be checked out, got %s", first, got)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "plugins", "bar.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected file added after the tag to be removed, got err=%v", err)
+	}
+	if err := ResetHard(dest, second); err != nil {
+		t.Fatalf("ResetHard() failed: %v", err)
+	}
+	if got, _ := HeadCommit(dest); got != second {
+		t.Errorf("expected reset to %s, got %s", second, got)
+	}
+	if err := EnsureUpdated(context.Background(), remote, dest, "missing"); err == nil {
+		t.Error("expected error updating to a missing ref")
+	}
+
+	// commits that exist locally are checked out without fetching
+	if err := os.Rename(remote, remote+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{first, first[:7]} {
+		if err := EnsureUpdated(context.Background(), remote, dest, ref); err != nil {
+			t.Fatalf("EnsureUpdated() to local commit %q failed: %v", ref, err)
+		}
+		if got, _ := HeadCommit(dest); got != first {
+			t.Errorf("expected commit %s to be checked out, got %s", first, got)
+		}
+	}
+}
+
+func TestEnsureCloned_native_stopped(t *testing.T) {
+	t.Setenv(EnvGit, "native")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dest := filepath.Join(t.TempDir(), "index")
+	if err := ensureCloned(ctx, "https://example.com/index.git", dest); err == nil {
+		t.Fatal("expected error cloning with a stopped context")
+	}
+	if ok, _ := IsGitCloned(dest); ok {
+		t.Error("expected no repository to be left after a stopped clone")
+	}
+}
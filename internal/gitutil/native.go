@@ -0,0 +1,416 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitutil
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+func init() {
+	// Serve local repositories in-process, so that cloning them doesn't need
+	// the git-upload-pack binary either.
+	client.InstallProtocol("file", server.NewServer(localLoader{}))
+}
+
+// localLoader loads local bare repositories, and the .git directory of local
+// repositories with a working directory.
+type localLoader struct{}
+
+func (localLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	fs := osfs.New(ep.Path)
+	if _, err := fs.Stat(git.GitDirName); err == nil {
+		if fs, err = fs.Chroot(git.GitDirName); err != nil {
+			return nil, err
+		}
+	} else if _, err := fs.Stat("config"); err != nil {
+		return nil, transport.ErrRepositoryNotFound
+	}
+	return filesystem.NewStorage(fs, cache.NewObjectLRUDefault()), nil
+}
+
+// infiniteDepth is the depth to fetch the whole history of a shallow
+// repository with, as git fetch --unshallow does.
+const infiniteDepth = 0x7fffffff
+
+// isLocal reports whether uri is a local repository. Local repositories are
+// served in-process, which does not support shallow clones.
+func isLocal(uri string) bool {
+	ep, err := transport.NewEndpoint(uri)
+	return err != nil || ep.Protocol == "file"
+}
+
+// fetchDepth returns the depth to fetch into the repository at dir with,
+// which keeps a shallow repository shallow.
+func fetchDepth(dir string) int {
+	if isShallow(dir) {
+		return 1
+	}
+	return 0
+}
+
+// progress returns where to write the progress of transfers, or nil to not
+// report it.
+func progress() io.Writer {
+	if klog.V(2).Enabled() {
+		return os.Stderr
+	}
+	return nil
+}
+
+// stopped returns an error that tells that operation op was stopped if ctx
+// is done, or err otherwise.
+func stopped(ctx context.Context, op string, err error) error {
+	if ctx.Err() != nil {
+		return errors.Wrapf(ctx.Err(), "git %s was stopped", op)
+	}
+	return err
+}
+
+// nativeClone clones uri into destinationPath like systemClone does, except
+// that remote repositories are not cloned blobless, as go-git doesn't support
+// partial clones: the clone is shallow and sparse, but contains the blobs of
+// all files of the commit.
+func nativeClone(ctx context.Context, uri, destinationPath string) error {
+	klog.V(4).Infof("Cloning %s into %s", uri, destinationPath)
+	opts := &git.CloneOptions{
+		URL:          uri,
+		SingleBranch: true,
+		NoCheckout:   true,
+		Progress:     progress(),
+	}
+	if !isLocal(uri) {
+		opts.Depth = 1
+	}
+	repo, err := git.PlainCloneContext(ctx, destinationPath, false, opts)
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) || errors.Is(err, plumbing.ErrReferenceNotFound) ||
+		errors.Is(err, git.NoMatchingRefSpecError{}) {
+		// The remote has no commits, or none on its HEAD. Like git, leave an
+		// empty repository that tracks the remote.
+		repo, err := git.PlainInit(destinationPath, false)
+		if err != nil {
+			return errors.Wrapf(err, "failed to initialize repository at %q", destinationPath)
+		}
+		_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{uri}})
+		return errors.Wrapf(err, "failed to add remote to repository at %q", destinationPath)
+	}
+	if err != nil {
+		return errors.Wrapf(stopped(ctx, "clone", err), "failed to clone %q", uri)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return errors.Wrapf(err, "failed to find HEAD of repository at %q", destinationPath)
+	}
+	if err := configureClone(repo, head); err != nil {
+		return errors.Wrapf(err, "failed to configure repository at %q", destinationPath)
+	}
+	return errors.Wrapf(nativeReset(repo, head.Hash()), "checkout of %q failed", destinationPath)
+}
+
+// configureClone makes a new clone track only the branch at head of the
+// remote, and only check out checkoutDirs, in the same way as git clone
+// --single-branch and git sparse-checkout set do.
+func configureClone(repo *git.Repository, head *plumbing.Reference) error {
+	branch := head.Name()
+	tracking := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch.Short())
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(tracking, head.Hash())); err != nil {
+		return err
+	}
+	if err := repo.Storer.RemoveReference(plumbing.NewRemoteHEADReferenceName(git.DefaultRemoteName)); err != nil {
+		return err
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Remotes[git.DefaultRemoteName].Fetch = []config.RefSpec{config.RefSpec("+" + branch.String() + ":" + tracking.String())}
+	cfg.Raw.Section("core").SetOption("sparseCheckout", "true").SetOption("sparseCheckoutCone", "true")
+	if err := repo.SetConfig(cfg); err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	patterns := []string{"/*", "!/*/"}
+	for _, d := range checkoutDirs {
+		patterns = append(patterns, "/"+d+"/")
+	}
+	infoDir := filepath.Join(wt.Filesystem.Root(), git.GitDirName, "info")
+	if err := os.MkdirAll(infoDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(infoDir, "sparse-checkout"), []byte(strings.Join(patterns, "\n")+"\n"), 0o644)
+}
+
+// sparsePaths returns the paths to check out in the repository at commit, or
+// nil to check out all files. As in the cone mode of git sparse-checkout,
+// these are checkoutDirs and the files at the top level of the repository.
+func sparsePaths(repo *git.Repository, commit plumbing.Hash) ([]string, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Raw.Section("core").Option("sparseCheckout") != "true" {
+		return nil, nil
+	}
+	var paths []string
+	for _, d := range checkoutDirs {
+		paths = append(paths, d+"/")
+	}
+	c, err := repo.CommitObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range tree.Entries {
+		if e.Mode.IsFile() {
+			paths = append(paths, e.Name)
+		}
+	}
+	return paths, nil
+}
+
+func nativeOpen(dir string) (*git.Repository, string, error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to open repository at %q", dir)
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to find remote of repository at %q", dir)
+	}
+	return repo, remote.Config().URLs[0], nil
+}
+
+func nativeFetch(ctx context.Context, repo *git.Repository, opts *git.FetchOptions) error {
+	opts.RemoteName = git.DefaultRemoteName
+	opts.Force = true
+	opts.Progress = progress()
+	err := repo.FetchContext(ctx, opts)
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return stopped(ctx, "fetch", err)
+}
+
+// nativeUpdate fetches the given branch, tag or commit, or the upstream of
+// the current branch if ref is empty, sets HEAD to it and creates a pristine
+// working directory.
+func nativeUpdate(ctx context.Context, destinationPath, ref string) error {
+	repo, _, err := nativeOpen(destinationPath)
+	if err != nil {
+		return err
+	}
+	var target plumbing.Hash
+	if ref == "" {
+		target, err = nativeFetchUpstream(ctx, repo, destinationPath)
+	} else {
+		target, err = nativeFetchRef(ctx, repo, destinationPath, ref)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fetch index at %q failed", destinationPath)
+	}
+	if err := nativeReset(repo, target); err != nil {
+		return errors.Wrapf(err, "reset index at %q failed", destinationPath)
+	}
+	return errors.Wrapf(nativeClean(repo, destinationPath), "clean index at %q failed", destinationPath)
+}
+
+// nativeFetchUpstream fetches the branches of the remote and returns the
+// commit of the branch that the current branch tracks.
+func nativeFetchUpstream(ctx context.Context, repo *git.Repository, dir string) (plumbing.Hash, error) {
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if head.Type() != plumbing.SymbolicReference {
+		return plumbing.ZeroHash, errors.New("HEAD is not on a branch, no upstream to update to")
+	}
+	branch := head.Target().Short()
+	upstream := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch)
+	cfg, err := repo.Config()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if b, ok := cfg.Branches[branch]; ok && b.Merge != "" {
+		upstream = plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())
+	}
+
+	if err := nativeFetch(ctx, repo, &git.FetchOptions{Depth: fetchDepth(dir)}); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	r, err := repo.Reference(upstream, true)
+	if err != nil {
+		return plumbing.ZeroHash, errors.Wrapf(err, "failed to find upstream %q of branch %q", upstream.Short(), branch)
+	}
+	return r.Hash(), nil
+}
+
+// nativeFetchRef fetches the given branch, tag or commit from the remote and
+// returns its commit.
+func nativeFetchRef(ctx context.Context, repo *git.Repository, dir, ref string) (plumbing.Hash, error) {
+	if h, err := resolveCommit(repo, ref); err == nil && isCommitOf(ref, h.String()) {
+		if _, err := repo.CommitObject(h); err == nil {
+			// Commits don't change, so there is nothing to fetch.
+			klog.V(2).Infof("Commit %q exists locally, not fetching it", ref)
+			return h, nil
+		}
+	}
+	candidates := []struct {
+		src, dst plumbing.ReferenceName
+	}{
+		{plumbing.NewBranchReferenceName(ref), plumbing.NewRemoteReferenceName(git.DefaultRemoteName, ref)},
+		{plumbing.NewTagReferenceName(ref), plumbing.NewTagReferenceName(ref)},
+	}
+	for _, c := range candidates {
+		spec := config.RefSpec("+" + c.src.String() + ":" + c.dst.String())
+		err := nativeFetch(ctx, repo, &git.FetchOptions{RefSpecs: []config.RefSpec{spec}, Depth: fetchDepth(dir)})
+		if err == nil {
+			return resolveCommit(repo, c.dst.String())
+		}
+		if ctx.Err() != nil {
+			return plumbing.ZeroHash, err
+		}
+		klog.V(2).Infof("Failed to fetch %q: %v", c.src, err)
+	}
+
+	// Commits can't be fetched by name, so fetch everything, including the
+	// history of shallow repositories, and look the ref up locally.
+	klog.V(2).Infof("Failed to fetch ref %q, fetching all refs instead", ref)
+	opts := &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec("+refs/heads/*:refs/remotes/" + git.DefaultRemoteName + "/*")},
+		Tags:     git.AllTags,
+	}
+	if isShallow(dir) {
+		opts.Depth = infiniteDepth
+	}
+	err := nativeFetch(ctx, repo, opts)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return resolveCommit(repo, ref)
+}
+
+func resolveCommit(repo *git.Repository, rev string) (plumbing.Hash, error) {
+	h, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, errors.Wrapf(err, "failed to resolve %q", rev)
+	}
+	return *h, nil
+}
+
+func nativeReset(repo *git.Repository, commit plumbing.Hash) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	paths, err := sparsePaths(repo, commit)
+	if err != nil {
+		return err
+	}
+	return wt.ResetSparsely(&git.ResetOptions{Commit: commit, Mode: git.HardReset}, paths)
+}
+
+// nativeClean removes the files and directories in the working directory at
+// dir that are not tracked, including ignored ones.
+func nativeClean(repo *git.Repository, dir string) error {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	tracked := map[string]bool{}
+	for _, e := range idx.Entries {
+		// Mark the file and all the directories that contain it.
+		for p := e.Name; p != "."; p = filepath.ToSlash(filepath.Dir(p)) {
+			tracked[p] = true
+		}
+	}
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if rel == git.GitDirName {
+			return filepath.SkipDir
+		}
+		if tracked[rel] {
+			return nil
+		}
+		klog.V(4).Infof("Removing untracked %s", rel)
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+func nativeHeadCommit(dir string) (string, error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open repository at %q", dir)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find HEAD of repository at %q", dir)
+	}
+	return head.Hash().String(), nil
+}
+
+func nativeResetHard(dir, commit string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+	h, err := resolveCommit(repo, commit)
+	if err != nil {
+		return err
+	}
+	return nativeReset(repo, h)
+}
+
+func nativeRemoteURL(dir string) (string, error) {
+	_, uri, err := nativeOpen(dir)
+	return uri, err
+}
@@ -0,0 +1,488 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package indexmetadata verifies the signed metadata of plugin indexes. It
+// follows the role model of The Update Framework (https://theupdateframework.io):
+//
+//   - root metadata lists the trusted keys of each role and is signed by the
+//     root keys. A new root version must be signed by the keys of the previous
+//     root version as well as its own.
+//   - targets metadata lists the sha256 sum of every file in the plugins
+//     directory of the index and of its list of retired plugins, protecting
+//     against arbitrary manifest changes.
+//   - timestamp metadata pins the current version and sum of the targets
+//     metadata, and expires quickly to protect against freeze attacks.
+//
+// Versions that are lower than the previously verified ones are refused to
+// protect against rollback attacks, as is metadata that differs from the
+// previously verified metadata of the same version.
+package indexmetadata
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/pkg/constants"
+)
+
+const (
+	// Dir is the directory of an index repository with the metadata files.
+	Dir = "metadata"
+
+	targetsFile   = "targets.json"
+	timestampFile = "timestamp.json"
+
+	roleRoot      = "root"
+	roleTargets   = "targets"
+	roleTimestamp = "timestamp"
+
+	keyTypeEd25519 = "ed25519"
+)
+
+// Trust is the state of an index that was last verified successfully, kept
+// locally to verify the next update of the index against.
+type Trust struct {
+	// Root is the trusted root metadata file. It is kept byte for byte, as
+	// signatures are made over the exact bytes of the signed content.
+	Root             []byte `json:"root"`
+	TargetsVersion   int64  `json:"targetsVersion"`
+	TimestampVersion int64  `json:"timestampVersion"`
+	// TargetsSha256 and TimestampSha256 are the sums of the signed content of
+	// the trusted targets and timestamp metadata. They are empty for trust
+	// stored by older versions of krew.
+	TargetsSha256   string `json:"targetsSha256,omitempty"`
+	TimestampSha256 string `json:"timestampSha256,omitempty"`
+}
+
+// signedFile is the envelope of a metadata file. Signatures are made over the
+// exact bytes of the Signed value as they appear in the file.
+type signedFile struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []signature     `json:"signatures"`
+}
+
+type signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type key struct {
+	KeyType string `json:"keytype"`
+	Public  string `json:"public"`
+}
+
+type role struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+type header struct {
+	Type    string    `json:"_type"`
+	Version int64     `json:"version"`
+	Expires time.Time `json:"expires"`
+}
+
+type rootMetadata struct {
+	header
+	Keys  map[string]key  `json:"keys"`
+	Roles map[string]role `json:"roles"`
+}
+
+type targetsMetadata struct {
+	header
+	Targets map[string]fileMeta `json:"targets"`
+}
+
+type timestampMetadata struct {
+	header
+	Meta map[string]fileMeta `json:"meta"`
+}
+
+type fileMeta struct {
+	Version int64  `json:"version,omitempty"`
+	Sha256  string `json:"sha256"`
+}
+
+// KeyID returns the identifier of a public key in the metadata, which is the
+// hex-encoded sha256 sum of the key.
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:])
+}
+
+// Bootstrap establishes the initial trust in the metadata of the index cloned
+// at indexDir, starting from the first root version. If rootKeys are given,
+// the latest root version must be signed by enough of them to meet the root
+// threshold; otherwise the first root version is trusted on first use. The
+// returned Trust still needs to be verified with Verify.
+func Bootstrap(indexDir string, rootKeys []string) (*Trust, error) {
+	raw, err := os.ReadFile(filepath.Join(indexDir, Dir, "1.root.json"))
+	if os.IsNotExist(err) {
+		return nil, errors.New("index has no signed metadata")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read root metadata")
+	}
+	f, root, err := parseRoot(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyRole(f, root, roleRoot, nil); err != nil {
+		return nil, err
+	}
+	raw, root, err = updateRoot(filepath.Join(indexDir, Dir), raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rootKeys) > 0 {
+		pinned := make(map[string]bool)
+		for _, k := range rootKeys {
+			pub, err := base64.StdEncoding.DecodeString(k)
+			if err != nil || len(pub) != ed25519.PublicKeySize {
+				return nil, errors.Errorf("root key %q is not a base64-encoded ed25519 public key", k)
+			}
+			pinned[KeyID(pub)] = true
+		}
+		f, _, err := parseRoot(raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyRole(f, root, roleRoot, pinned); err != nil {
+			return nil, errors.Wrap(err, "root metadata is not signed by the given root keys")
+		}
+	} else {
+		klog.V(1).Infof("Trusting root metadata version %d on first use", root.Version)
+	}
+	return &Trust{Root: raw}, nil
+}
+
+// Verify checks the metadata of the index cloned at indexDir against trust and
+// that every file in the plugins directory matches the signed targets. On
+// success, trust is updated to the verified state.
+func Verify(indexDir string, trust *Trust, now time.Time) error {
+	dir := filepath.Join(indexDir, Dir)
+	rootRaw, root, err := updateRoot(dir, trust.Root)
+	if err != nil {
+		return err
+	}
+	if err := checkExpiry(root.header, roleRoot, now); err != nil {
+		return err
+	}
+
+	tsFile, err := readSigned(filepath.Join(dir, timestampFile))
+	if err != nil {
+		return err
+	}
+	if err := verifyRole(tsFile, root, roleTimestamp, nil); err != nil {
+		return err
+	}
+	var ts timestampMetadata
+	if err := parseSigned(tsFile, roleTimestamp, &ts); err != nil {
+		return err
+	}
+	if ts.Version < trust.TimestampVersion {
+		return errors.Errorf("timestamp metadata version %d is older than the trusted version %d", ts.Version, trust.TimestampVersion)
+	}
+	tsSum := sha256Sum(tsFile.Signed)
+	if err := checkSameVersion(roleTimestamp, ts.Version, tsSum, trust.TimestampVersion, trust.TimestampSha256); err != nil {
+		return err
+	}
+	if err := checkExpiry(ts.header, roleTimestamp, now); err != nil {
+		return err
+	}
+	targetsMeta, ok := ts.Meta[targetsFile]
+	if !ok {
+		return errors.Errorf("timestamp metadata does not list %s", targetsFile)
+	}
+
+	targetsRaw, err := os.ReadFile(filepath.Join(dir, targetsFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to read targets metadata")
+	}
+	if err := checkSha256(targetsRaw, targetsMeta.Sha256); err != nil {
+		return errors.Wrap(err, "targets metadata does not match timestamp")
+	}
+	var tf signedFile
+	if err := json.Unmarshal(targetsRaw, &tf); err != nil {
+		return errors.Wrap(err, "failed to parse targets metadata")
+	}
+	if err := verifyRole(tf, root, roleTargets, nil); err != nil {
+		return err
+	}
+	var targets targetsMetadata
+	if err := parseSigned(tf, roleTargets, &targets); err != nil {
+		return err
+	}
+	if targets.Version != targetsMeta.Version {
+		return errors.Errorf("targets metadata version %d does not match version %d in timestamp", targets.Version, targetsMeta.Version)
+	}
+	if targets.Version < trust.TargetsVersion {
+		return errors.Errorf("targets metadata version %d is older than the trusted version %d", targets.Version, trust.TargetsVersion)
+	}
+	targetsSum := sha256Sum(tf.Signed)
+	if err := checkSameVersion(roleTargets, targets.Version, targetsSum, trust.TargetsVersion, trust.TargetsSha256); err != nil {
+		return err
+	}
+	if err := checkExpiry(targets.header, roleTargets, now); err != nil {
+		return err
+	}
+	if err := verifyTargets(indexDir, targets.Targets); err != nil {
+		return err
+	}
+
+	klog.V(2).Infof("Verified index metadata (root=%d, targets=%d, timestamp=%d)", root.Version, targets.Version, ts.Version)
+	*trust = Trust{
+		Root:             rootRaw,
+		TargetsVersion:   targets.Version,
+		TimestampVersion: ts.Version,
+		TargetsSha256:    targetsSum,
+		TimestampSha256:  tsSum,
+	}
+	return nil
+}
+
+// checkSameVersion refuses metadata of the role that has the trusted version
+// but differs from the trusted metadata of that version.
+func checkSameVersion(roleName string, version int64, sum string, trustedVersion int64, trustedSum string) error {
+	if version == trustedVersion && trustedSum != "" && sum != trustedSum {
+		return errors.Errorf("%s metadata version %d differs from the trusted metadata of the same version", roleName, version)
+	}
+	return nil
+}
+
+// updateRoot walks the newer root versions in dir that follow the trusted
+// root, and returns the latest one.
+func updateRoot(dir string, trustedRaw []byte) ([]byte, *rootMetadata, error) {
+	_, root, err := parseRoot(trustedRaw)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid trusted root metadata")
+	}
+	raw := trustedRaw
+	for {
+		next := root.Version + 1
+		nextRaw, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%d.root.json", next)))
+		if os.IsNotExist(err) {
+			return raw, root, nil
+		} else if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to read root metadata version %d", next)
+		}
+		f, nextRoot, err := parseRoot(nextRaw)
+		if err != nil {
+			return nil, nil, err
+		}
+		if nextRoot.Version != next {
+			return nil, nil, errors.Errorf("root metadata file for version %d has version %d", next, nextRoot.Version)
+		}
+		if err := verifyRole(f, root, roleRoot, nil); err != nil {
+			return nil, nil, errors.Wrapf(err, "root metadata version %d is not signed by the previous root keys", next)
+		}
+		if err := verifyRole(f, nextRoot, roleRoot, nil); err != nil {
+			return nil, nil, errors.Wrapf(err, "root metadata version %d is not signed by its own root keys", next)
+		}
+		klog.V(2).Infof("Updated trusted root metadata to version %d", next)
+		raw, root = nextRaw, nextRoot
+	}
+}
+
+// verifyTargets checks that each file in the plugins directory of the index,
+// and the list of retired plugins if the index has one, is listed in targets
+// with a matching sum, and that none of these files listed in targets are
+// missing.
+func verifyTargets(indexDir string, targets map[string]fileMeta) error {
+	for name := range targets {
+		if name != constants.RetiredPluginsFile && !strings.HasPrefix(name, "plugins/") {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return errors.Errorf("signed targets list invalid path %q", name)
+		}
+		if _, err := os.Lstat(filepath.Join(indexDir, filepath.FromSlash(name))); os.IsNotExist(err) {
+			return errors.Errorf("%s is listed in the signed targets but missing", name)
+		} else if err != nil {
+			return err
+		}
+	}
+	retired := filepath.Join(indexDir, constants.RetiredPluginsFile)
+	if _, err := os.Stat(retired); err == nil {
+		if err := verifyTarget(indexDir, retired, targets); err != nil {
+			return err
+		}
+	}
+	pluginsDir := filepath.Join(indexDir, "plugins")
+	return filepath.WalkDir(pluginsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == pluginsDir {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return verifyTarget(indexDir, path, targets)
+	})
+}
+
+func verifyTarget(indexDir, path string, targets map[string]fileMeta) error {
+	rel, err := filepath.Rel(indexDir, path)
+	if err != nil {
+		return err
+	}
+	name := filepath.ToSlash(rel)
+	meta, ok := targets[name]
+	if !ok {
+		return errors.Errorf("%s is not listed in the signed targets", name)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(checkSha256(b, meta.Sha256), "%s does not match the signed targets", name)
+}
+
+func readSigned(path string) (signedFile, error) {
+	var f signedFile
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return f, errors.Wrapf(err, "failed to read %s", filepath.Base(path))
+	}
+	return f, errors.Wrapf(json.Unmarshal(b, &f), "failed to parse %s", filepath.Base(path))
+}
+
+func parseSigned(f signedFile, wantType string, v interface{}) error {
+	if err := json.Unmarshal(f.Signed, v); err != nil {
+		return errors.Wrapf(err, "failed to parse %s metadata", wantType)
+	}
+	var h header
+	if err := json.Unmarshal(f.Signed, &h); err != nil {
+		return errors.Wrapf(err, "failed to parse %s metadata", wantType)
+	}
+	if h.Type != wantType {
+		return errors.Errorf("expected %s metadata, got %q", wantType, h.Type)
+	}
+	return nil
+}
+
+func parseRoot(raw []byte) (signedFile, *rootMetadata, error) {
+	var f signedFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return f, nil, errors.Wrap(err, "failed to parse root metadata")
+	}
+	var root rootMetadata
+	if err := parseSigned(f, roleRoot, &root); err != nil {
+		return f, nil, err
+	}
+	for id, k := range root.Keys {
+		if k.KeyType != keyTypeEd25519 {
+			return f, nil, errors.Errorf("key %s has unsupported type %q", id, k.KeyType)
+		}
+		pub, err := base64.StdEncoding.DecodeString(k.Public)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return f, nil, errors.Errorf("key %s is not a base64-encoded ed25519 public key", id)
+		}
+		if KeyID(pub) != id {
+			return f, nil, errors.Errorf("key id %s does not match its public key", id)
+		}
+	}
+	return f, &root, nil
+}
+
+// verifyRole checks that f is signed by enough distinct keys of the role in
+// root to meet its threshold. If pinned is non-nil, only signatures by the
+// pinned keys are counted.
+func verifyRole(f signedFile, root *rootMetadata, roleName string, pinned map[string]bool) error {
+	r, ok := root.Roles[roleName]
+	if !ok || r.Threshold < 1 {
+		return errors.Errorf("root metadata does not define a valid %s role", roleName)
+	}
+	allowed := make(map[string]bool, len(r.KeyIDs))
+	for _, id := range r.KeyIDs {
+		allowed[id] = true
+	}
+	valid := make(map[string]bool)
+	for _, s := range f.Signatures {
+		if !allowed[s.KeyID] || valid[s.KeyID] || (pinned != nil && !pinned[s.KeyID]) {
+			continue
+		}
+		k, ok := root.Keys[s.KeyID]
+		if !ok {
+			continue
+		}
+		pub, _ := base64.StdEncoding.DecodeString(k.Public)
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(pub, f.Signed, sig) {
+			valid[s.KeyID] = true
+		}
+	}
+	if len(valid) < r.Threshold {
+		return errors.Errorf("%s metadata has %d valid signatures, %d required", roleName, len(valid), r.Threshold)
+	}
+	return nil
+}
+
+func checkExpiry(h header, roleName string, now time.Time) error {
+	if !now.Before(h.Expires) {
+		return errors.Errorf("%s metadata expired on %s", roleName, h.Expires.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func sha256Sum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func checkSha256(b []byte, want string) error {
+	if got := sha256Sum(b); got != strings.ToLower(want) {
+		return errors.Errorf("sha256 sum is %s, expected %s", got, want)
+	}
+	return nil
+}
+
+// LoadTrust reads the trust state stored at path.
+func LoadTrust(path string) (*Trust, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Trust
+	return &t, errors.Wrapf(json.Unmarshal(b, &t), "failed to parse trusted index metadata at %q", path)
+}
+
+// SaveTrust stores the trust state at path.
+func SaveTrust(path string, t *Trust) error {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create directory for trusted index metadata")
+	}
+	return errors.Wrap(os.WriteFile(path, b, 0o644), "failed to store trusted index metadata")
+}
@@ -0,0 +1,439 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmetadata
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/constants"
+)
+
+var (
+	now     = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	future  = now.Add(24 * time.Hour)
+	past    = now.Add(-24 * time.Hour)
+	rootKey = testKey(1)
+	tgtKey  = testKey(2)
+	tsKey   = testKey(3)
+	newRoot = testKey(4)
+	other   = testKey(5)
+)
+
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func publicKey(k ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(k.Public().(ed25519.PublicKey))
+}
+
+func keyID(k ed25519.PrivateKey) string { return KeyID(k.Public().(ed25519.PublicKey)) }
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// signed returns a metadata file of v signed by the keys.
+func signed(t *testing.T, v interface{}, keys ...ed25519.PrivateKey) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sigs []signature
+	for _, k := range keys {
+		sigs = append(sigs, signature{KeyID: keyID(k), Sig: base64.StdEncoding.EncodeToString(ed25519.Sign(k, b))})
+	}
+	s, err := json.Marshal(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(fmt.Sprintf(`{"signed": %s, "signatures": %s}`, b, s))
+}
+
+func rootOf(version int64, root, targets, timestamp ed25519.PrivateKey) rootMetadata {
+	r := rootMetadata{
+		header: header{Type: roleRoot, Version: version, Expires: future},
+		Keys:   map[string]key{},
+		Roles:  map[string]role{},
+	}
+	for name, k := range map[string]ed25519.PrivateKey{roleRoot: root, roleTargets: targets, roleTimestamp: timestamp} {
+		r.Keys[keyID(k)] = key{KeyType: keyTypeEd25519, Public: publicKey(k)}
+		r.Roles[name] = role{KeyIDs: []string{keyID(k)}, Threshold: 1}
+	}
+	return r
+}
+
+// testIndex is an index with valid metadata for a single plugin.
+type testIndex struct {
+	*testutil.TempDir
+	t *testing.T
+}
+
+func newTestIndex(t *testing.T) testIndex {
+	idx := testIndex{testutil.NewTempDir(t), t}
+	idx.Write("plugins/foo.yaml", []byte("name: foo"))
+	idx.Write("metadata/1.root.json", signed(t, rootOf(1, rootKey, tgtKey, tsKey), rootKey))
+	idx.writeTargets(1, future, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))}})
+	idx.writeTimestamp(1, future, tsKey)
+	return idx
+}
+
+func (idx testIndex) read(file string) []byte {
+	b, err := os.ReadFile(idx.Path(file))
+	if err != nil {
+		idx.t.Fatal(err)
+	}
+	return b
+}
+
+func (idx testIndex) writeTargets(version int64, expires time.Time, k ed25519.PrivateKey, targets map[string]fileMeta) {
+	idx.Write("metadata/targets.json", signed(idx.t, targetsMetadata{
+		header:  header{Type: roleTargets, Version: version, Expires: expires},
+		Targets: targets,
+	}, k))
+}
+
+func (idx testIndex) writeTimestamp(version int64, expires time.Time, k ed25519.PrivateKey) {
+	targets := idx.read("metadata/targets.json")
+	var tf signedFile
+	if err := json.Unmarshal(targets, &tf); err != nil {
+		idx.t.Fatal(err)
+	}
+	var tm targetsMetadata
+	if err := json.Unmarshal(tf.Signed, &tm); err != nil {
+		idx.t.Fatal(err)
+	}
+	idx.Write("metadata/timestamp.json", signed(idx.t, timestampMetadata{
+		header: header{Type: roleTimestamp, Version: version, Expires: expires},
+		Meta:   map[string]fileMeta{targetsFile: {Version: tm.Version, Sha256: sha256Hex(targets)}},
+	}, k))
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(idx testIndex)
+		trust   Trust
+		wantErr bool
+	}{
+		{
+			name:   "valid metadata",
+			modify: func(testIndex) {},
+		},
+		{
+			name: "newer versions",
+			modify: func(idx testIndex) {
+				idx.writeTargets(3, future, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))}})
+				idx.writeTimestamp(7, future, tsKey)
+			},
+			trust: Trust{TargetsVersion: 2, TimestampVersion: 6},
+		},
+		{
+			name: "modified plugin manifest",
+			modify: func(idx testIndex) {
+				idx.Write("plugins/foo.yaml", []byte("name: evil"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "plugin manifest not in targets",
+			modify: func(idx testIndex) {
+				idx.Write("plugins/bar.yaml", []byte("name: bar"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "signed retired plugins",
+			modify: func(idx testIndex) {
+				idx.Write("retired.yaml", []byte("plugins: []"))
+				idx.writeTargets(3, future, tgtKey, map[string]fileMeta{
+					"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))},
+					"retired.yaml":     {Sha256: sha256Hex([]byte("plugins: []"))},
+				})
+				idx.writeTimestamp(3, future, tsKey)
+			},
+		},
+		{
+			name: "retired plugins not in targets",
+			modify: func(idx testIndex) {
+				idx.Write("retired.yaml", []byte("plugins: []"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "targets signed by the wrong key",
+			modify: func(idx testIndex) {
+				idx.writeTargets(1, future, other, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))}})
+				idx.writeTimestamp(1, future, tsKey)
+			},
+			wantErr: true,
+		},
+		{
+			name: "targets changed after timestamp",
+			modify: func(idx testIndex) {
+				idx.writeTargets(1, future, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: evil"))}})
+				idx.Write("plugins/foo.yaml", []byte("name: evil"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "timestamp signed by targets key",
+			modify: func(idx testIndex) {
+				idx.writeTimestamp(1, future, tgtKey)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired timestamp",
+			modify: func(idx testIndex) {
+				idx.writeTimestamp(1, past, tsKey)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired targets",
+			modify: func(idx testIndex) {
+				idx.writeTargets(1, past, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))}})
+				idx.writeTimestamp(1, future, tsKey)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "timestamp rollback",
+			modify:  func(testIndex) {},
+			trust:   Trust{TimestampVersion: 2},
+			wantErr: true,
+		},
+		{
+			name:    "targets rollback",
+			modify:  func(testIndex) {},
+			trust:   Trust{TargetsVersion: 2},
+			wantErr: true,
+		},
+		{
+			name: "signed plugin manifest missing",
+			modify: func(idx testIndex) {
+				idx.writeTargets(1, future, tgtKey, map[string]fileMeta{
+					"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))},
+					"plugins/bar.yaml": {Sha256: sha256Hex([]byte("name: bar"))},
+				})
+				idx.writeTimestamp(1, future, tsKey)
+			},
+			wantErr: true,
+		},
+		{
+			name: "signed retired plugins missing",
+			modify: func(idx testIndex) {
+				idx.writeTargets(1, future, tgtKey, map[string]fileMeta{
+					"plugins/foo.yaml":           {Sha256: sha256Hex([]byte("name: foo"))},
+					constants.RetiredPluginsFile: {Sha256: sha256Hex([]byte("plugins: []"))},
+				})
+				idx.writeTimestamp(1, future, tsKey)
+			},
+			wantErr: true,
+		},
+		{
+			name: "root rotation signed by old and new keys",
+			modify: func(idx testIndex) {
+				idx.Write("metadata/2.root.json", signed(idx.t, rootOf(2, newRoot, tgtKey, other), rootKey, newRoot))
+				idx.writeTimestamp(1, future, other)
+			},
+		},
+		{
+			name: "root rotation signed only by new keys",
+			modify: func(idx testIndex) {
+				idx.Write("metadata/2.root.json", signed(idx.t, rootOf(2, newRoot, tgtKey, other), newRoot))
+				idx.writeTimestamp(1, future, other)
+			},
+			wantErr: true,
+		},
+		{
+			name: "root rotation with wrong version",
+			modify: func(idx testIndex) {
+				idx.Write("metadata/2.root.json", signed(idx.t, rootOf(3, rootKey, tgtKey, tsKey), rootKey))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := newTestIndex(t)
+			trust := tt.trust
+			trust.Root = idx.read("metadata/1.root.json")
+			tt.modify(idx)
+
+			err := Verify(idx.Root(), &trust, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify_updatesTrust(t *testing.T) {
+	idx := newTestIndex(t)
+	idx.Write("metadata/2.root.json", signed(t, rootOf(2, rootKey, tgtKey, tsKey), rootKey))
+	idx.writeTargets(4, future, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))}})
+	idx.writeTimestamp(9, future, tsKey)
+
+	trust := Trust{Root: idx.read("metadata/1.root.json")}
+	if err := Verify(idx.Root(), &trust, now); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(trust.Root, idx.read("metadata/2.root.json")) {
+		t.Errorf("expected trusted root to be updated to version 2")
+	}
+	if trust.TargetsVersion != 4 || trust.TimestampVersion != 9 {
+		t.Errorf("trusted versions = (%d, %d), expected (4, 9)", trust.TargetsVersion, trust.TimestampVersion)
+	}
+
+	// going back to older metadata is now refused
+	idx.writeTargets(3, future, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: foo"))}})
+	idx.writeTimestamp(9, future, tsKey)
+	if err := Verify(idx.Root(), &trust, now); err == nil {
+		t.Error("expected error verifying older targets")
+	}
+}
+
+func TestVerify_sameVersionChanged(t *testing.T) {
+	idx := newTestIndex(t)
+	trust := Trust{Root: idx.read("metadata/1.root.json")}
+	if err := Verify(idx.Root(), &trust, now); err != nil {
+		t.Fatal(err)
+	}
+	if trust.TargetsSha256 == "" || trust.TimestampSha256 == "" {
+		t.Fatalf("expected sums of the trusted metadata, got %+v", trust)
+	}
+
+	// the same metadata is verified again
+	if err := Verify(idx.Root(), &trust, now); err != nil {
+		t.Errorf("expected same metadata to verify again: %v", err)
+	}
+
+	// different timestamp of the same version
+	trusted := trust
+	idx.writeTimestamp(1, future.Add(time.Hour), tsKey)
+	if err := Verify(idx.Root(), &trust, now); err == nil {
+		t.Error("expected error verifying changed timestamp of the same version")
+	}
+
+	// different targets of the same version
+	trust = trusted
+	idx.Write("plugins/foo.yaml", []byte("name: bar"))
+	idx.writeTargets(1, future, tgtKey, map[string]fileMeta{"plugins/foo.yaml": {Sha256: sha256Hex([]byte("name: bar"))}})
+	idx.writeTimestamp(2, future, tsKey)
+	if err := Verify(idx.Root(), &trust, now); err == nil {
+		t.Error("expected error verifying changed targets of the same version")
+	}
+
+	// trust stored without sums accepts the same version
+	trust = Trust{Root: trusted.Root, TargetsVersion: 1, TimestampVersion: 1}
+	if err := Verify(idx.Root(), &trust, now); err != nil {
+		t.Errorf("expected trust without sums to verify: %v", err)
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		rootKeys []string
+		noMeta   bool
+		wantErr  bool
+	}{
+		{
+			name: "trust on first use",
+		},
+		{
+			name:     "pinned root key",
+			rootKeys: []string{publicKey(rootKey)},
+		},
+		{
+			name:     "different root key",
+			rootKeys: []string{publicKey(other)},
+			wantErr:  true,
+		},
+		{
+			name:     "malformed root key",
+			rootKeys: []string{"not a key"},
+			wantErr:  true,
+		},
+		{
+			name:    "no metadata",
+			noMeta:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := newTestIndex(t)
+			if tt.noMeta {
+				if err := os.RemoveAll(idx.Path("metadata")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			trust, err := Bootstrap(idx.Root(), tt.rootKeys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := Verify(idx.Root(), trust, now); err != nil {
+				t.Fatalf("Verify() after Bootstrap() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestBootstrap_pinsLatestRoot(t *testing.T) {
+	idx := newTestIndex(t)
+	idx.Write("metadata/2.root.json", signed(t, rootOf(2, newRoot, tgtKey, tsKey), rootKey, newRoot))
+
+	if _, err := Bootstrap(idx.Root(), []string{publicKey(newRoot)}); err != nil {
+		t.Errorf("Bootstrap() with the current root key failed: %v", err)
+	}
+	if _, err := Bootstrap(idx.Root(), []string{publicKey(rootKey)}); err == nil {
+		t.Error("expected Bootstrap() with a rotated out root key to fail")
+	}
+}
+
+func TestTrust_saveAndLoad(t *testing.T) {
+	idx := newTestIndex(t)
+	want := &Trust{Root: idx.read("metadata/1.root.json"), TargetsVersion: 2, TimestampVersion: 3}
+	path := filepath.Join(idx.Root(), "trust.json")
+	if err := SaveTrust(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadTrust(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Root, want.Root) || got.TargetsVersion != 2 || got.TimestampVersion != 3 {
+		t.Errorf("LoadTrust() = %+v, want %+v", got, want)
+	}
+}
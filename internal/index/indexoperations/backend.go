@@ -0,0 +1,413 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/krew/internal/download"
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/gitutil"
+	"sigs.k8s.io/krew/internal/index/validation"
+	"sigs.k8s.io/krew/pkg/constants"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// Backends that the local copy of an index can be fetched with.
+const (
+	// BackendGit clones the index as a git repository.
+	BackendGit = "git"
+	// BackendArchive downloads the index as a .tar.gz, .tgz or .zip archive
+	// over HTTP.
+	BackendArchive = "archive"
+	// BackendCatalog downloads the index as a JSON catalog of plugin
+	// manifests over HTTP.
+	BackendCatalog = "catalog"
+	// BackendLocal reads the plugin manifests from a local directory.
+	BackendLocal = "local"
+)
+
+// sourceFile stores where an index that is not a git repository was fetched
+// from, relative to the index directory.
+const sourceFile = ".krew-source.json"
+
+// source describes where an index that is not a git repository was fetched
+// from. The ETag, Last-Modified time and digest are only set for indexes
+// downloaded over HTTP.
+type source struct {
+	Backend      string `json:"backend"`
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Digest       string `json:"digest"`
+}
+
+// catalog is the document served by a BackendCatalog index.
+type catalog struct {
+	Plugins []index.Plugin  `json:"plugins"`
+	Retired []RetiredPlugin `json:"retired,omitempty"`
+}
+
+// DetectBackend returns the backend that an index at the given URL is
+// fetched with. HTTP URLs of archives and JSON files are downloaded, file://
+// URLs of directories other than git repositories ending in .git are read
+// directly, and all other URLs are cloned with git.
+func DetectBackend(uri string) string {
+	u, err := url.Parse(uri)
+	if err == nil && u.Scheme == "file" && !strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), ".git") {
+		return BackendLocal
+	}
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return BackendGit
+	}
+	p := strings.ToLower(u.Path)
+	switch {
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"), strings.HasSuffix(p, ".zip"):
+		return BackendArchive
+	case strings.HasSuffix(p, ".json"):
+		return BackendCatalog
+	default:
+		return BackendGit
+	}
+}
+
+// IndexBackend returns the backend the local copy of the named index was
+// fetched with. It returns an error if the index directory is neither a git
+// repository nor a downloaded index.
+func IndexBackend(paths environment.Paths, name string) (string, error) {
+	dir := paths.IndexPath(name)
+	src, err := loadSource(dir)
+	if err == nil {
+		return src.Backend, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	ok, err := gitutil.IsGitCloned(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check local index git repository")
+	} else if !ok {
+		return "", errors.Errorf("invalid index %q, neither a git repository nor a downloaded index found in index folder", name)
+	}
+	return BackendGit, nil
+}
+
+// RemoteURL returns the URL the named index is fetched from.
+func RemoteURL(paths environment.Paths, name string) (string, error) {
+	backend, err := IndexBackend(paths, name)
+	if err != nil {
+		return "", err
+	}
+	if backend == BackendGit {
+		return gitutil.GetRemoteURL(paths.IndexPath(name))
+	}
+	src, err := loadSource(paths.IndexPath(name))
+	return src.URL, err
+}
+
+// Revision returns the version of the local copy of the named index: the
+// checked out commit for git, or the digest of the download for indexes
+// fetched over HTTP. Local indexes have no revision.
+func Revision(paths environment.Paths, name string) (string, error) {
+	backend, err := IndexBackend(paths, name)
+	if err != nil {
+		return "", err
+	}
+	if backend == BackendGit {
+		return gitutil.HeadCommit(paths.IndexPath(name))
+	}
+	src, err := loadSource(paths.IndexPath(name))
+	return src.Digest, err
+}
+
+// fetchHTTPIndex downloads the index at uri with the given backend and
+// replaces dir with it. Requests are conditional on the ETag and
+// Last-Modified time of the previous download, so that an unchanged index is
+// not downloaded again. Downloads larger than the limit of the size of
+// extracted archives are refused. If check is not nil, it must succeed on the new
+// version of the index, otherwise dir is left unchanged.
+func fetchHTTPIndex(ctx context.Context, uri, backend, dir string, check func(dir string) error) error {
+	prev, err := loadSource(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if prev.URL != uri || prev.Backend != backend {
+		prev = source{}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, http.NoBody)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %q", uri)
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+	maxSize, err := download.MaxExtractSize()
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("Fetching index %q", uri)
+	resp, err := download.NewHTTPClient(nil).Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download index %q", uri)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && prev.Digest != "" {
+		klog.V(1).Infof("Index %q is not modified", uri)
+		return runCheck(check, dir)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to download index %q, status code %d", uri, resp.StatusCode)
+	}
+	body := io.Reader(resp.Body)
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download index %q", uri)
+	}
+	if maxSize > 0 && int64(len(b)) > maxSize {
+		return errors.Errorf("refusing to download index %q larger than %d bytes (limit set by KREW_MAX_EXTRACT_SIZE)", uri, maxSize)
+	}
+	sum := sha256.Sum256(b)
+	src := source{
+		Backend:      backend,
+		URL:          uri,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Digest:       "sha256:" + hex.EncodeToString(sum[:]),
+	}
+	if src.Digest == prev.Digest {
+		klog.V(1).Infof("Index %q is unchanged", uri)
+		if err := runCheck(check, dir); err != nil {
+			return err
+		}
+		return saveSource(dir, src)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create index directory")
+	}
+	// Hidden directories in the index folder are not listed as indexes.
+	staging, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory for index")
+	}
+	defer os.RemoveAll(staging)
+
+	newDir := filepath.Join(staging, "index")
+	switch backend {
+	case BackendArchive:
+		newDir, err = extractIndexArchive(staging, b)
+	case BackendCatalog:
+		err = writeCatalog(newDir, b)
+	default:
+		err = errors.Errorf("unsupported index backend %q", backend)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read index %q", uri)
+	}
+	if err := saveSource(newDir, src); err != nil {
+		return err
+	}
+	if err := runCheck(check, newDir); err != nil {
+		return err
+	}
+	return replaceDir(newDir, dir, staging)
+}
+
+// linkLocalIndex makes dir an index whose plugins directory links to the
+// directory of the file:// URL uri, or to its plugins subdirectory if it has
+// one. In that case, the list of retired plugins of the index is linked to the
+// one in the directory of uri, whether it exists or not. Existing links in dir
+// are replaced.
+func linkLocalIndex(uri, dir string) error {
+	target, err := localIndexPath(uri)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(target); err != nil {
+		return errors.Wrapf(err, "failed to read local index %q", uri)
+	} else if !fi.IsDir() {
+		return errors.Errorf("local index %q is not a directory", uri)
+	}
+	var retiredTarget string
+	if fi, err := os.Stat(filepath.Join(target, "plugins")); err == nil && fi.IsDir() {
+		retiredTarget = filepath.Join(target, constants.RetiredPluginsFile)
+		target = filepath.Join(target, "plugins")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create index directory")
+	}
+	link := filepath.Join(dir, "plugins")
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove previous link of local index")
+	}
+	klog.V(2).Infof("Linking %q to local index %q", link, target)
+	if err := os.Symlink(target, link); err != nil {
+		return errors.Wrap(err, "failed to link local index")
+	}
+	retiredLink := filepath.Join(dir, constants.RetiredPluginsFile)
+	if err := os.Remove(retiredLink); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove previous link of retired plugins of local index")
+	}
+	if retiredTarget != "" {
+		if err := os.Symlink(retiredTarget, retiredLink); err != nil {
+			return errors.Wrap(err, "failed to link retired plugins of local index")
+		}
+	}
+	return saveSource(dir, source{Backend: BackendLocal, URL: uri})
+}
+
+// localIndexPath returns the absolute path of the directory of a file:// URL.
+func localIndexPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse url %q", uri)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", errors.Errorf("local index url %q must not have a host other than localhost", uri)
+	}
+	p := filepath.FromSlash(u.Path)
+	if runtime.GOOS == "windows" {
+		// file:///C:/dir has the path /C:/dir
+		p = strings.TrimPrefix(p, `\`)
+	}
+	if !filepath.IsAbs(p) {
+		return "", errors.Errorf("local index url %q must have an absolute path", uri)
+	}
+	return filepath.Clean(p), nil
+}
+
+func runCheck(check func(string) error, dir string) error {
+	if check == nil {
+		return nil
+	}
+	return check(dir)
+}
+
+// extractIndexArchive extracts the archive into a directory in staging and
+// returns the root of the index in it, which is either the top-level
+// directory or its only subdirectory if the archive has a single top-level
+// directory, as in archives of source repositories.
+func extractIndexArchive(staging string, b []byte) (string, error) {
+	root := filepath.Join(staging, "archive")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", errors.Wrap(err, "failed to create directory for index archive")
+	}
+	if err := download.ExtractArchive(root, bytes.NewReader(b), int64(len(b))); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(root, "plugins")); err == nil {
+		return root, nil
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read extracted index")
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		sub := filepath.Join(root, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(sub, "plugins")); err == nil {
+			return sub, nil
+		}
+	}
+	return "", errors.New("archive does not contain a plugins directory")
+}
+
+// writeCatalog writes the plugin manifests of the JSON catalog as the plugins
+// directory of an index in dir, along with its retired plugins.
+func writeCatalog(dir string, b []byte) error {
+	var c catalog
+	if err := json.Unmarshal(b, &c); err != nil {
+		return errors.Wrap(err, "failed to parse catalog")
+	}
+	pluginsDir := filepath.Join(dir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create plugins directory")
+	}
+	for _, p := range c.Plugins {
+		if !validation.IsSafePluginName(p.Name) {
+			return errors.Errorf("catalog contains plugin with unsafe name %q", p.Name)
+		}
+		y, err := yaml.Marshal(p)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert manifest of plugin %q to yaml", p.Name)
+		}
+		if err := os.WriteFile(filepath.Join(pluginsDir, p.Name+constants.ManifestExtension), y, 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write manifest of plugin %q", p.Name)
+		}
+	}
+	return writeRetiredPlugins(dir, c.Retired)
+}
+
+// replaceDir moves newDir to dir, replacing dir if it exists. The previous
+// version is moved into staging first, so that it is removed with it.
+func replaceDir(newDir, dir, staging string) error {
+	previous := filepath.Join(staging, "previous")
+	if _, err := os.Stat(dir); err == nil {
+		if err := os.Rename(dir, previous); err != nil {
+			return errors.Wrap(err, "failed to move previous version of index")
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	} else {
+		previous = ""
+	}
+	if err := os.Rename(newDir, dir); err != nil {
+		if previous != "" {
+			if rbErr := os.Rename(previous, dir); rbErr != nil {
+				klog.Warningf("failed to restore previous version of index %q: %v", dir, rbErr)
+			}
+		}
+		return errors.Wrap(err, "failed to move index into place")
+	}
+	return nil
+}
+
+func loadSource(dir string) (source, error) {
+	var src source
+	b, err := os.ReadFile(filepath.Join(dir, sourceFile))
+	if err != nil {
+		return src, err
+	}
+	return src, errors.Wrap(json.Unmarshal(b, &src), "failed to parse index source")
+}
+
+func saveSource(dir string, src source) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode index source")
+	}
+	return errors.Wrap(os.WriteFile(filepath.Join(dir, sourceFile), b, 0o644), "failed to write index source")
+}
@@ -0,0 +1,322 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func TestDetectBackend(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/kubernetes-sigs/krew-index.git": BackendGit,
+		"git@github.com:kubernetes-sigs/krew-index.git":     BackendGit,
+		"/path/to/index":                         BackendGit,
+		"https://example.com/index.tar.gz":       BackendArchive,
+		"http://example.com/index.TGZ?token=1":   BackendArchive,
+		"https://example.com/index.zip":          BackendArchive,
+		"https://example.com/index/catalog.json": BackendCatalog,
+		"file:///home/foo/plugins":               BackendLocal,
+		"file:///home/foo/index.git":             BackendGit,
+	}
+	for url, want := range tests {
+		if got := DetectBackend(url); got != want {
+			t.Errorf("DetectBackend(%q) = %q, expected %q", url, got, want)
+		}
+	}
+}
+
+// indexServer serves body at any path with an ETag and counts the requests
+// that were answered with the full body.
+type indexServer struct {
+	body      []byte
+	etag      string
+	downloads int
+}
+
+func (s *indexServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.downloads++
+	w.Header().Set("ETag", s.etag)
+	_, _ = w.Write(s.body)
+}
+
+func TestAddIndex_catalog(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+
+	foo := testutil.NewPlugin().WithName("foo").V()
+	b, err := json.Marshal(catalog{Plugins: []index.Plugin{foo}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &indexServer{body: b, etag: `"v1"`}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	url := server.URL + "/catalog.json"
+
+	if err := AddIndex(paths, "foo", url, IndexConfig{}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+	got, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath("foo"), "foo")
+	if err != nil {
+		t.Fatalf("failed to load plugin from catalog index: %v", err)
+	}
+	if diff := cmp.Diff(foo, got); diff != "" {
+		t.Errorf("plugin from catalog mismatch: %s", diff)
+	}
+	indexes, err := ListIndexes(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]Index{{Name: "foo", URL: url}}, indexes); diff != "" {
+		t.Errorf("ListIndexes() mismatch: %s", diff)
+	}
+	if backend, err := IndexBackend(paths, "foo"); err != nil || backend != BackendCatalog {
+		t.Errorf("IndexBackend() = %q, %v, expected %q", backend, err, BackendCatalog)
+	}
+	rev, err := Revision(paths, "foo")
+	if err != nil || rev == "" {
+		t.Errorf("Revision() = %q, %v, expected a digest", rev, err)
+	}
+
+	if err := UpdateIndex(context.Background(), paths, indexes[0]); err != nil {
+		t.Fatalf("UpdateIndex() failed: %v", err)
+	}
+	if srv.downloads != 1 {
+		t.Errorf("expected unmodified index not to be downloaded again, got %d downloads", srv.downloads)
+	}
+
+	retired := RetiredPlugin{Name: "foo", Replacement: "bar"}
+	srv.body, err = json.Marshal(catalog{Retired: []RetiredPlugin{retired}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.etag = `"v2"`
+	if err := UpdateIndex(context.Background(), paths, indexes[0]); err != nil {
+		t.Fatalf("UpdateIndex() failed: %v", err)
+	}
+	if _, err := os.Stat(paths.IndexPluginsPath("foo") + "/foo.yaml"); !os.IsNotExist(err) {
+		t.Errorf("expected plugin removed from catalog to be removed from index, got err=%v", err)
+	}
+	if got, err := RetiredPlugins(paths, "foo"); err != nil || got["foo"] != retired {
+		t.Errorf("RetiredPlugins() = %v, %v, expected retired plugin from catalog", got, err)
+	}
+	if newRev, _ := Revision(paths, "foo"); newRev == rev {
+		t.Errorf("expected revision to change after update")
+	}
+}
+
+func TestAddIndex_catalogLimits(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	b, err := json.Marshal(catalog{Plugins: []index.Plugin{testutil.NewPlugin().WithName("foo").V()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &indexServer{body: b, etag: `"v1"`}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	url := server.URL + "/catalog.json"
+
+	if err := AddIndex(paths, "foo", url, IndexConfig{VerifyMetadata: true}); err == nil {
+		t.Error("expected error adding catalog index with metadata verification")
+	}
+	if srv.downloads != 0 {
+		t.Errorf("expected catalog not to be downloaded, got %d downloads", srv.downloads)
+	}
+
+	t.Setenv("KREW_MAX_EXTRACT_SIZE", strconv.Itoa(len(b)-1))
+	if err := AddIndex(paths, "foo", url, IndexConfig{}); err == nil {
+		t.Error("expected error adding catalog index larger than the limit")
+	}
+	if _, err := os.Stat(paths.IndexPath("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected index not to be added, got err=%v", err)
+	}
+}
+
+func TestAddIndex_archive(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	manifest, err := yaml.Marshal(testutil.NewPlugin().WithName("foo").V())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []struct {
+		name string
+		body []byte
+	}{
+		{"krew-index-master/", nil},
+		{"krew-index-master/plugins/", nil},
+		{"krew-index-master/plugins/foo.yaml", manifest},
+	}
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.body)), Typeflag: tar.TypeReg}
+		if f.body == nil {
+			hdr.Mode, hdr.Typeflag = 0o755, tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(&indexServer{body: buf.Bytes(), etag: `"v1"`})
+	defer server.Close()
+
+	if err := AddIndex(paths, "foo", server.URL+"/index.tar.gz", IndexConfig{}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+	if _, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath("foo"), "foo"); err != nil {
+		t.Errorf("failed to load plugin from archive index: %v", err)
+	}
+	entries, err := os.ReadDir(paths.IndexBase())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected temporary directories to be removed, got %d entries in index folder", len(entries))
+	}
+}
+
+func TestAddIndex_local(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	tmpDir.WriteYAML("dev/plugins/foo.yaml", testutil.NewPlugin().WithName("foo").V())
+	url := fileURL(tmpDir.Path("dev"))
+
+	if err := AddIndex(paths, "dev", url, IndexConfig{}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+	if _, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath("dev"), "foo"); err != nil {
+		t.Errorf("failed to load plugin from local index: %v", err)
+	}
+	tmpDir.WriteYAML("dev/plugins/bar.yaml", testutil.NewPlugin().WithName("bar").V())
+	if _, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath("dev"), "bar"); err != nil {
+		t.Errorf("expected new manifest to be read without update: %v", err)
+	}
+	if got, err := RemoteURL(paths, "dev"); err != nil || got != url {
+		t.Errorf("RemoteURL() = %q, %v, expected %q", got, err, url)
+	}
+	if err := UpdateIndex(context.Background(), paths, Index{Name: "dev", URL: url}); err != nil {
+		t.Errorf("UpdateIndex() failed: %v", err)
+	}
+
+	if got, err := RetiredPlugins(paths, "dev"); err != nil || len(got) != 0 {
+		t.Errorf("RetiredPlugins() = %v, %v, expected none", got, err)
+	}
+	tmpDir.Write("dev/retired.yaml", []byte("plugins: [{name: foo, replacement: bar}]"))
+	if got, err := RetiredPlugins(paths, "dev"); err != nil || got["foo"].Replacement != "bar" {
+		t.Errorf("RetiredPlugins() = %v, %v, expected retired plugin from local index", got, err)
+	}
+
+	if err := DeleteIndex(paths, "dev"); err != nil {
+		t.Fatalf("DeleteIndex() failed: %v", err)
+	}
+	if _, err := os.Stat(tmpDir.Path("dev/plugins/foo.yaml")); err != nil {
+		t.Errorf("expected local index directory to be kept after removing the index: %v", err)
+	}
+	if err := AddIndex(paths, "missing", fileURL(tmpDir.Path("missing")), IndexConfig{}); err == nil {
+		t.Error("expected error adding local index of missing directory")
+	}
+}
+
+func fileURL(path string) string {
+	return "file:///" + strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
+
+func TestIndexBackend_invalid(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	tmpDir.Write("index/foo/plugins/foo.yaml", nil)
+
+	if _, err := IndexBackend(paths, "foo"); err == nil {
+		t.Error("expected error for index that is neither cloned nor downloaded")
+	}
+}
+
+func TestUpdateIndex_timeout(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	b, err := json.Marshal(catalog{Plugins: []index.Plugin{testutil.NewPlugin().WithName("foo").V()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hang atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hang.Load() {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	defer server.Close()
+	url := server.URL + "/catalog.json"
+
+	if err := AddIndex(paths, "foo", url, IndexConfig{}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+	added, err := LastUpdated(paths, "foo")
+	if err != nil {
+		t.Fatalf("LastUpdated() failed: %v", err)
+	}
+
+	hang.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := UpdateIndex(ctx, paths, Index{Name: "foo", URL: url}); err == nil {
+		t.Fatal("expected update of hanging index to time out")
+	}
+	if _, err := indexscanner.LoadPluginByName(paths.IndexPluginsPath("foo"), "foo"); err != nil {
+		t.Errorf("expected local copy to be kept after failed update: %v", err)
+	}
+	if got, err := LastUpdated(paths, "foo"); err != nil || !got.Equal(added) {
+		t.Errorf("LastUpdated() = %v, %v, expected failed update not to change it from %v", got, err, added)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexscanner"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// catalogCacheVersion is increased when the format of the catalog cache
+// changes, so that caches written by other versions of krew are rebuilt.
+const catalogCacheVersion = 1
+
+// catalogCache is the file that the parsed plugin manifests of an index are
+// cached in.
+type catalogCache struct {
+	Version int `json:"version"`
+	// Revision is the revision of the index that the plugins were read from.
+	Revision string         `json:"revision"`
+	Plugins  []index.Plugin `json:"plugins"`
+}
+
+// Catalog is the list of valid plugins of an index. Plugins are indexed by
+// name and, the first time an os/arch pair is looked up, by whether they are
+// available for it. Descriptions are not indexed, as they are matched fuzzily
+// by search, which needs to look at all of them. A Catalog is not safe for
+// concurrent use.
+type Catalog struct {
+	pluginsDir string
+	plugins    []index.Plugin
+	byName     map[string]int
+	// byPlatform maps os/arch pairs to whether each plugin is available.
+	byPlatform map[string][]bool
+}
+
+func newCatalog(pluginsDir string, plugins []index.Plugin) *Catalog {
+	c := &Catalog{
+		pluginsDir: pluginsDir,
+		plugins:    plugins,
+		byName:     make(map[string]int, len(plugins)),
+		byPlatform: make(map[string][]bool),
+	}
+	for i, p := range plugins {
+		c.byName[p.Name] = i
+	}
+	return c
+}
+
+// Plugins returns the plugins of the index.
+func (c *Catalog) Plugins() []index.Plugin {
+	return c.plugins
+}
+
+// Plugin returns the plugin with the given name. Plugins that are not in the
+// catalog are read from their manifest in the index, to return why they could
+// not be loaded, or an error that can be checked with os.IsNotExist if the
+// plugin does not exist.
+func (c *Catalog) Plugin(name string) (index.Plugin, error) {
+	if i, ok := c.byName[name]; ok {
+		return c.plugins[i], nil
+	}
+	return indexscanner.LoadPluginByName(c.pluginsDir, name)
+}
+
+// Available reports whether the named plugin has a platform for goos and
+// goarch. It returns false for plugins that are not in the catalog.
+func (c *Catalog) Available(name, goos, goarch string) (bool, error) {
+	i, ok := c.byName[name]
+	if !ok {
+		return false, nil
+	}
+	key := goos + "/" + goarch
+	available, ok := c.byPlatform[key]
+	if !ok {
+		var err error
+		if available, err = availablePlugins(c.plugins, goos, goarch); err != nil {
+			return false, err
+		}
+		c.byPlatform[key] = available
+	}
+	return available[i], nil
+}
+
+// availablePlugins returns whether each of the plugins has a platform whose
+// selector matches goos and goarch.
+func availablePlugins(plugins []index.Plugin, goos, goarch string) ([]bool, error) {
+	env := labels.Set{"os": goos, "arch": goarch}
+	out := make([]bool, len(plugins))
+	for i, p := range plugins {
+		for _, platform := range p.Spec.Platforms {
+			sel, err := metav1.LabelSelectorAsSelector(platform.Selector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compile label selector of plugin %q", p.Name)
+			}
+			if sel.Matches(env) {
+				out[i] = true
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// LoadCatalog returns the catalog of the named index. It is read from the
+// cache of the index if the cache was built from the current revision of the
+// index, and from the plugin manifests in the index otherwise, in which case
+// the cache is rebuilt. Local indexes are always read from their manifests.
+func LoadCatalog(paths environment.Paths, name string) (*Catalog, error) {
+	rev, err := Revision(paths, name)
+	if err != nil {
+		klog.V(1).Infof("Failed to get revision of index %q, not using the catalog cache: %v", name, err)
+	}
+	if rev != "" {
+		plugins, err := loadCatalogCache(paths.IndexCatalogPath(name), rev)
+		if err == nil {
+			klog.V(4).Infof("Loaded %d plugins of index %q from the catalog cache", len(plugins), name)
+			return newCatalog(paths.IndexPluginsPath(name), plugins), nil
+		}
+		klog.V(2).Infof("Scanning index %q: %v", name, err)
+	}
+	return buildCatalog(paths, name, rev)
+}
+
+// RebuildCatalog reads the plugin manifests in the named index and caches
+// them for LoadCatalog.
+func RebuildCatalog(paths environment.Paths, name string) error {
+	rev, err := Revision(paths, name)
+	if err != nil {
+		return err
+	}
+	_, err = buildCatalog(paths, name, rev)
+	return err
+}
+
+// buildCatalog reads the plugin manifests in the named index and, unless rev
+// is empty, caches them as the plugins at revision rev. Failure to write the
+// cache is only logged.
+func buildCatalog(paths environment.Paths, name, rev string) (*Catalog, error) {
+	plugins, err := indexscanner.LoadPluginListFromFS(paths.IndexPluginsPath(name))
+	if err != nil {
+		return nil, err
+	}
+	if rev != "" {
+		if err := saveCatalogCache(paths.IndexCatalogPath(name), catalogCache{Version: catalogCacheVersion, Revision: rev, Plugins: plugins}); err != nil {
+			klog.Warningf("failed to cache the plugins of index %q: %v", name, err)
+		}
+	}
+	return newCatalog(paths.IndexPluginsPath(name), plugins), nil
+}
+
+func loadCatalogCache(path, rev string) ([]index.Plugin, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read catalog cache")
+	}
+	var c catalogCache
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, errors.Wrap(err, "failed to parse catalog cache")
+	}
+	if c.Version != catalogCacheVersion || c.Revision != rev {
+		return nil, errors.Errorf("catalog cache of version %d at revision %q is outdated", c.Version, c.Revision)
+	}
+	return c.Plugins, nil
+}
+
+// saveCatalogCache writes the cache to a temporary file first, so that
+// concurrent readers never see a partially written cache.
+func saveCatalogCache(path string, c catalogCache) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode catalog cache")
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create cache directory")
+	}
+	f, err := os.CreateTemp(dir, ".catalog-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create catalog cache")
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write catalog cache")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "failed to write catalog cache")
+	}
+	return errors.Wrap(os.Rename(f.Name(), path), "failed to replace catalog cache")
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func catalogNames(t *testing.T, paths environment.Paths, name string) []string {
+	t.Helper()
+	c, err := LoadCatalog(paths, name)
+	if err != nil {
+		t.Fatalf("LoadCatalog() failed: %v", err)
+	}
+	var names []string
+	for _, p := range c.Plugins() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestLoadCatalog(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	b, err := json.Marshal(catalog{Plugins: []index.Plugin{testutil.NewPlugin().WithName("foo").V()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &indexServer{body: b, etag: `"v1"`}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	url := server.URL + "/catalog.json"
+	if err := AddIndex(paths, "foo", url, IndexConfig{}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"foo"}, catalogNames(t, paths, "foo")); diff != "" {
+		t.Errorf("catalog built from manifests mismatch: %s", diff)
+	}
+	if _, err := os.Stat(paths.IndexCatalogPath("foo")); err != nil {
+		t.Fatalf("expected catalog cache to be written: %v", err)
+	}
+
+	// The cache is used while the revision of the index is unchanged.
+	rev, err := Revision(paths, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := catalogCache{Version: catalogCacheVersion, Revision: rev, Plugins: []index.Plugin{testutil.NewPlugin().WithName("cached").V()}}
+	if err := saveCatalogCache(paths.IndexCatalogPath("foo"), cached); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"cached"}, catalogNames(t, paths, "foo")); diff != "" {
+		t.Errorf("catalog from cache mismatch: %s", diff)
+	}
+	c, err := LoadCatalog(paths, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Plugin("foo"); err != nil {
+		t.Errorf("expected plugin missing from the cache to be read from the index: %v", err)
+	}
+	if _, err := c.Plugin("bar"); !os.IsNotExist(err) {
+		t.Errorf("expected not found error for missing plugin, got %v", err)
+	}
+
+	// Corrupt caches are ignored and rebuilt.
+	if err := os.WriteFile(paths.IndexCatalogPath("foo"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"foo"}, catalogNames(t, paths, "foo")); diff != "" {
+		t.Errorf("catalog with corrupt cache mismatch: %s", diff)
+	}
+	if _, err := loadCatalogCache(paths.IndexCatalogPath("foo"), rev); err != nil {
+		t.Errorf("expected corrupt cache to be rebuilt: %v", err)
+	}
+
+	// Updates rebuild the cache for the new revision.
+	srv.body, err = json.Marshal(catalog{Plugins: []index.Plugin{testutil.NewPlugin().WithName("bar").V()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.etag = `"v2"`
+	if err := UpdateIndex(context.Background(), paths, Index{Name: "foo", URL: url}); err != nil {
+		t.Fatalf("UpdateIndex() failed: %v", err)
+	}
+	newRev, err := Revision(paths, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins, err := loadCatalogCache(paths.IndexCatalogPath("foo"), newRev)
+	if err != nil {
+		t.Fatalf("expected cache to be rebuilt after update: %v", err)
+	}
+	if len(plugins) != 1 || plugins[0].Name != "bar" {
+		t.Errorf("expected rebuilt cache to have the updated plugins, got %v", plugins)
+	}
+
+	if err := DeleteIndex(paths, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.IndexCatalogPath("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected catalog cache to be removed with the index, got err=%v", err)
+	}
+}
+
+func TestLoadCatalog_local(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	tmpDir.WriteYAML("dev/plugins/foo.yaml", testutil.NewPlugin().WithName("foo").V())
+	if err := AddIndex(paths, "dev", fileURL(tmpDir.Path("dev")), IndexConfig{}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"foo"}, catalogNames(t, paths, "dev")); diff != "" {
+		t.Errorf("catalog of local index mismatch: %s", diff)
+	}
+	if _, err := os.Stat(paths.IndexCatalogPath("dev")); !os.IsNotExist(err) {
+		t.Errorf("expected no catalog cache for local index, got err=%v", err)
+	}
+}
+
+func TestCatalog_Available(t *testing.T) {
+	c := newCatalog("", []index.Plugin{
+		testutil.NewPlugin().WithName("linux").WithPlatforms(testutil.NewPlatform().WithOSArch("linux", "amd64").V()).V(),
+		testutil.NewPlugin().WithName("both").WithPlatforms(
+			testutil.NewPlatform().WithOSArch("linux", "amd64").V(),
+			testutil.NewPlatform().WithOSArch("darwin", "arm64").V()).V(),
+	})
+	tests := []struct {
+		name, os, arch string
+		expected       bool
+	}{
+		{"linux", "linux", "amd64", true},
+		{"linux", "darwin", "arm64", false},
+		{"both", "darwin", "arm64", true},
+		{"both", "windows", "amd64", false},
+		{"missing", "linux", "amd64", false},
+	}
+	for _, tt := range tests {
+		got, err := c.Available(tt.name, tt.os, tt.arch)
+		if err != nil {
+			t.Fatalf("Available(%q, %q, %q) failed: %v", tt.name, tt.os, tt.arch, err)
+		}
+		if got != tt.expected {
+			t.Errorf("Available(%q, %q, %q) = %v, expected %v", tt.name, tt.os, tt.arch, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/krew/internal/environment"
+)
+
+// Signature policies of an index decide what happens when a plugin archive
+// from the index has a missing or invalid signature.
+const (
+	// SignaturePolicyWarn verifies signatures when present and prints a
+	// warning if verification fails.
+	SignaturePolicyWarn = "warn"
+	// SignaturePolicyEnforce refuses to install plugins without a valid
+	// signature by one of the trusted keys of the index.
+	SignaturePolicyEnforce = "enforce"
+)
+
+var validSchemePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+
+// IndexConfig holds the local settings of a configured index.
+type IndexConfig struct {
+	// SignaturePolicy is either SignaturePolicyWarn (the default, if unset)
+	// or SignaturePolicyEnforce.
+	SignaturePolicy string `json:"signaturePolicy,omitempty"`
+
+	// TrustedKeys maps key names that plugin signatures can refer to with
+	// keyRef to base64-encoded public keys.
+	TrustedKeys map[string]string `json:"trustedKeys,omitempty"`
+
+	// VerifyMetadata enables verification of the signed metadata of the
+	// index when it is added and after each update.
+	VerifyMetadata bool `json:"verifyMetadata,omitempty"`
+
+	// RootKeys are the base64-encoded public keys the root metadata of the
+	// index must be signed with when it is added. If VerifyMetadata is set
+	// and there are no root keys, the root metadata is trusted on first use.
+	RootKeys []string `json:"rootKeys,omitempty"`
+
+	// AllowedSchemes restricts the URI schemes, such as "https", that plugins
+	// from the index can be downloaded with. Any scheme is allowed if empty.
+	AllowedSchemes []string `json:"allowedSchemes,omitempty"`
+
+	// AllowedHosts restricts the hosts that plugins from the index can be
+	// downloaded from. An entry like "*.example.com" allows all subdomains of
+	// example.com. Any host is allowed if empty.
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+
+	// Ref is the git branch, tag or commit the index is pinned to. The
+	// default branch of the remote is tracked if empty.
+	Ref string `json:"ref,omitempty"`
+
+	// Priority orders indexes when resolving plugin names without an index.
+	// Such names refer to the plugin in the index with the highest priority
+	// that has it.
+	Priority int `json:"priority,omitempty"`
+}
+
+// Validate checks that the settings have valid values.
+func (c IndexConfig) Validate() error {
+	switch c.SignaturePolicy {
+	case "", SignaturePolicyWarn, SignaturePolicyEnforce:
+	default:
+		return errors.Errorf("invalid signature policy %q, must be %q or %q", c.SignaturePolicy, SignaturePolicyWarn, SignaturePolicyEnforce)
+	}
+	if len(c.RootKeys) > 0 && !c.VerifyMetadata {
+		return errors.New("root keys can only be set when metadata verification is enabled")
+	}
+	if strings.HasPrefix(c.Ref, "-") || strings.ContainsAny(c.Ref, " \t\n") {
+		return errors.Errorf("invalid ref %q", c.Ref)
+	}
+	for _, scheme := range c.AllowedSchemes {
+		if !validSchemePattern.MatchString(scheme) {
+			return errors.Errorf("invalid allowed scheme %q", scheme)
+		}
+	}
+	for _, host := range c.AllowedHosts {
+		if host == "" || host == "*." || strings.ContainsAny(host, "/:@") || strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+			return errors.Errorf("invalid allowed host %q", host)
+		}
+	}
+	return nil
+}
+
+// CheckURI returns an error if the download policy of the index does not
+// allow downloading from uri.
+func (c IndexConfig) CheckURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse uri %q", uri)
+	}
+	if len(c.AllowedSchemes) > 0 && !containsFold(c.AllowedSchemes, u.Scheme) {
+		return errors.Errorf("uri %q is not allowed by the index, scheme must be one of: %s", uri, strings.Join(c.AllowedSchemes, ", "))
+	}
+	if len(c.AllowedHosts) > 0 && !c.isAllowedHost(u.Hostname()) {
+		return errors.Errorf("uri %q is not allowed by the index, host must be one of: %s", uri, strings.Join(c.AllowedHosts, ", "))
+	}
+	return nil
+}
+
+func (c IndexConfig) isAllowedHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range c.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix := strings.TrimPrefix(allowed, "*"); suffix != allowed {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadPolicy returns a short description of where plugins from the index
+// can be downloaded from.
+func (c IndexConfig) DownloadPolicy() string {
+	var parts []string
+	if len(c.AllowedSchemes) > 0 {
+		parts = append(parts, "schemes="+strings.Join(c.AllowedSchemes, ","))
+	}
+	if len(c.AllowedHosts) > 0 {
+		parts = append(parts, "hosts="+strings.Join(c.AllowedHosts, ","))
+	}
+	if len(parts) == 0 {
+		return "any"
+	}
+	return strings.Join(parts, " ")
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadIndexConfig reads the settings of the named index. Indexes without
+// stored settings have a zero IndexConfig.
+func LoadIndexConfig(paths environment.Paths, name string) (IndexConfig, error) {
+	var cfg IndexConfig
+	b, err := os.ReadFile(paths.IndexConfigPath(name))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, errors.Wrapf(err, "failed to read config of index %q", name)
+	}
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return cfg, errors.Wrapf(err, "failed to parse config of index %q", name)
+	}
+	return cfg, errors.Wrapf(cfg.Validate(), "invalid config of index %q", name)
+}
+
+// SaveIndexConfig stores the settings of the named index.
+func SaveIndexConfig(paths environment.Paths, name string, cfg IndexConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert index config to yaml")
+	}
+	path := paths.IndexConfigPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create index config directory")
+	}
+	return errors.Wrapf(os.WriteFile(path, b, 0o644), "failed to write config of index %q", name)
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/testutil"
+)
+
+func TestIndexConfig(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+
+	cfg, err := LoadIndexConfig(paths, "foo")
+	if err != nil {
+		t.Fatalf("LoadIndexConfig() of index without config failed: %v", err)
+	}
+	if diff := cmp.Diff(IndexConfig{}, cfg); diff != "" {
+		t.Errorf("expected zero config: %s", diff)
+	}
+
+	want := IndexConfig{
+		SignaturePolicy: SignaturePolicyEnforce,
+		TrustedKeys:     map[string]string{"publisher": "key"},
+	}
+	if err := SaveIndexConfig(paths, "foo", want); err != nil {
+		t.Fatalf("SaveIndexConfig() failed: %v", err)
+	}
+	got, err := LoadIndexConfig(paths, "foo")
+	if err != nil {
+		t.Fatalf("LoadIndexConfig() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("loaded config does not match: %s", diff)
+	}
+
+	if err := SaveIndexConfig(paths, "foo", IndexConfig{SignaturePolicy: "sometimes"}); err == nil {
+		t.Error("expected error saving config with invalid signature policy")
+	}
+	if err := SaveIndexConfig(paths, "foo", IndexConfig{RootKeys: []string{"key"}}); err == nil {
+		t.Error("expected error saving config with root keys but no metadata verification")
+	}
+	tmpDir.Write("config/index/bar.yaml", []byte("signaturePolicy: sometimes"))
+	if _, err := LoadIndexConfig(paths, "bar"); err == nil {
+		t.Error("expected error loading config with invalid signature policy")
+	}
+}
+
+func TestDeleteIndex_removesConfig(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	tmpDir.InitEmptyGitRepo(paths.IndexPath("foo"), "")
+	if err := SaveIndexConfig(paths, "foo", IndexConfig{SignaturePolicy: SignaturePolicyWarn}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteIndex(paths, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.IndexConfigPath("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected index config to be removed, got: %v", err)
+	}
+}
+
+func TestIndexConfig_CheckURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     IndexConfig
+		uri     string
+		wantErr bool
+	}{
+		{
+			name: "no policy",
+			uri:  "ftp://anywhere.example.com/foo.tar.gz",
+		},
+		{
+			name: "allowed scheme",
+			cfg:  IndexConfig{AllowedSchemes: []string{"https"}},
+			uri:  "HTTPS://example.com/foo.tar.gz",
+		},
+		{
+			name:    "disallowed scheme",
+			cfg:     IndexConfig{AllowedSchemes: []string{"https"}},
+			uri:     "http://example.com/foo.tar.gz",
+			wantErr: true,
+		},
+		{
+			name: "allowed host with port",
+			cfg:  IndexConfig{AllowedHosts: []string{"artifacts.corp.example"}},
+			uri:  "https://Artifacts.Corp.Example:8443/foo.tar.gz",
+		},
+		{
+			name:    "disallowed host",
+			cfg:     IndexConfig{AllowedHosts: []string{"artifacts.corp.example"}},
+			uri:     "https://artifacts.corp.example.evil.com/foo.tar.gz",
+			wantErr: true,
+		},
+		{
+			name: "subdomain wildcard",
+			cfg:  IndexConfig{AllowedHosts: []string{"*.corp.example"}},
+			uri:  "https://a.b.corp.example/foo.tar.gz",
+		},
+		{
+			name:    "wildcard does not match the domain itself",
+			cfg:     IndexConfig{AllowedHosts: []string{"*.corp.example"}},
+			uri:     "https://corp.example/foo.tar.gz",
+			wantErr: true,
+		},
+		{
+			name:    "wildcard does not match suffix of other domain",
+			cfg:     IndexConfig{AllowedHosts: []string{"*.corp.example"}},
+			uri:     "https://evilcorp.example/foo.tar.gz",
+			wantErr: true,
+		},
+		{
+			name:    "userinfo is not the host",
+			cfg:     IndexConfig{AllowedHosts: []string{"artifacts.corp.example"}},
+			uri:     "https://artifacts.corp.example@evil.com/foo.tar.gz",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.CheckURI(tt.uri); (err != nil) != tt.wantErr {
+				t.Errorf("CheckURI(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIndexConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     IndexConfig
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "valid policy", cfg: IndexConfig{AllowedSchemes: []string{"https"}, AllowedHosts: []string{"a.example", "*.b.example"}}},
+		{name: "invalid scheme", cfg: IndexConfig{AllowedSchemes: []string{"https://"}}, wantErr: true},
+		{name: "host with port", cfg: IndexConfig{AllowedHosts: []string{"a.example:443"}}, wantErr: true},
+		{name: "host with path", cfg: IndexConfig{AllowedHosts: []string{"a.example/foo"}}, wantErr: true},
+		{name: "wildcard in the middle", cfg: IndexConfig{AllowedHosts: []string{"a.*.example"}}, wantErr: true},
+		{name: "bare wildcard", cfg: IndexConfig{AllowedHosts: []string{"*."}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIndexConfig_DownloadPolicy(t *testing.T) {
+	tests := []struct {
+		cfg  IndexConfig
+		want string
+	}{
+		{cfg: IndexConfig{}, want: "any"},
+		{cfg: IndexConfig{AllowedSchemes: []string{"https"}}, want: "schemes=https"},
+		{
+			cfg:  IndexConfig{AllowedSchemes: []string{"https"}, AllowedHosts: []string{"a.example", "*.b.example"}},
+			want: "schemes=https hosts=a.example,*.b.example",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.DownloadPolicy(); got != tt.want {
+			t.Errorf("DownloadPolicy() = %q, want %q", got, tt.want)
+		}
+	}
+}
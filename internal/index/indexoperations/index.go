@@ -15,13 +15,18 @@
 package indexoperations
 
 import (
+	"context"
 	"os"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/krew/internal/environment"
 	"sigs.k8s.io/krew/internal/gitutil"
+	"sigs.k8s.io/krew/internal/index/indexmetadata"
 )
 
 var validNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
@@ -42,11 +47,11 @@ func ListIndexes(paths environment.Paths) ([]Index, error) {
 
 	indexes := []Index{}
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
 			continue
 		}
 		indexName := e.Name()
-		remote, err := gitutil.GetRemoteURL(paths.IndexPath(indexName))
+		remote, err := RemoteURL(paths, indexName)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list the remote URL for index %s", indexName)
 		}
@@ -59,17 +64,175 @@ func ListIndexes(paths environment.Paths) ([]Index, error) {
 	return indexes, nil
 }
 
-// AddIndex initializes a new index to install plugins from.
-func AddIndex(paths environment.Paths, name, url string) error {
+// AddIndex initializes a new index to install plugins from, with the given
+// settings. The index is cloned with git, downloaded over HTTP or linked from
+// a local directory, depending on the backend detected from its URL.
+func AddIndex(paths environment.Paths, name, url string, cfg IndexConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
 	dir := paths.IndexPath(name)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return gitutil.EnsureCloned(url, dir)
+		backend := DetectBackend(url)
+		if cfg.Ref != "" && backend != BackendGit {
+			return errors.Errorf("refs are only supported for git indexes, not %s indexes", backend)
+		}
+		if cfg.VerifyMetadata && (backend == BackendLocal || backend == BackendCatalog) {
+			return errors.Errorf("metadata verification is not supported for %s indexes", backend)
+		}
+		var err error
+		switch backend {
+		case BackendGit:
+			err = gitutil.EnsureCloned(url, dir)
+			if err == nil && cfg.Ref != "" {
+				if err = gitutil.EnsureUpdated(context.Background(), url, dir, cfg.Ref); err != nil {
+					if rmErr := os.RemoveAll(dir); rmErr != nil {
+						klog.Warningf("failed to remove index %q that failed to check out %q: %v", name, cfg.Ref, rmErr)
+					}
+				}
+			}
+		case BackendLocal:
+			err = linkLocalIndex(url, dir)
+		default:
+			err = fetchHTTPIndex(context.Background(), url, backend, dir, nil)
+		}
+		if err != nil {
+			return err
+		}
+		if cfg.VerifyMetadata {
+			if err := bootstrapTrust(paths, name, cfg.RootKeys); err != nil {
+				if rmErr := os.RemoveAll(dir); rmErr != nil {
+					klog.Warningf("failed to remove index %q that failed verification: %v", name, rmErr)
+				}
+				return errors.Wrap(err, "failed to verify index metadata")
+			}
+		}
+		if err := SaveIndexConfig(paths, name, cfg); err != nil {
+			return err
+		}
+		return saveState(paths, name, indexState{LastUpdated: time.Now().UTC()})
 	} else if err != nil {
 		return err
 	}
 	return errors.New("index already exists")
 }
 
+// UpdateIndex fetches the latest version of the index, or of the ref it is
+// pinned to, records the time of the update and rebuilds the catalog cache of
+// the index. The update is stopped when
+// ctx is done. Local indexes are read from their directory directly, so
+// updating them only checks that it still exists. If the index has metadata
+// verification enabled and the new version fails verification, the update is
+// refused and the index is kept at its previous version.
+func UpdateIndex(ctx context.Context, paths environment.Paths, idx Index) error {
+	if err := updateIndex(ctx, paths, idx); err != nil {
+		return err
+	}
+	if err := RebuildCatalog(paths, idx.Name); err != nil {
+		klog.V(1).Infof("Failed to rebuild the catalog of index %q: %v", idx.Name, err)
+	}
+	return saveState(paths, idx.Name, indexState{LastUpdated: time.Now().UTC()})
+}
+
+func updateIndex(ctx context.Context, paths environment.Paths, idx Index) error {
+	dir := paths.IndexPath(idx.Name)
+	cfg, err := LoadIndexConfig(paths, idx.Name)
+	if err != nil {
+		return err
+	}
+	backend, err := IndexBackend(paths, idx.Name)
+	if err != nil {
+		return err
+	}
+	if backend == BackendLocal {
+		return linkLocalIndex(idx.URL, dir)
+	}
+	if !cfg.VerifyMetadata {
+		if backend != BackendGit {
+			return fetchHTTPIndex(ctx, idx.URL, backend, dir, nil)
+		}
+		return gitutil.EnsureUpdated(ctx, idx.URL, dir, cfg.Ref)
+	}
+
+	trust, err := indexmetadata.LoadTrust(paths.IndexTrustPath(idx.Name))
+	if err != nil {
+		return errors.Wrapf(err, "failed to load trusted metadata of index %q", idx.Name)
+	}
+	if backend != BackendGit {
+		// A download that fails verification is not moved into place.
+		err := fetchHTTPIndex(ctx, idx.URL, backend, dir, func(newDir string) error {
+			return errors.Wrap(indexmetadata.Verify(newDir, trust, time.Now()), "refusing index update that failed verification")
+		})
+		if err != nil {
+			return err
+		}
+		return indexmetadata.SaveTrust(paths.IndexTrustPath(idx.Name), trust)
+	}
+	prev, err := gitutil.HeadCommit(dir)
+	if err != nil {
+		return err
+	}
+	if err := gitutil.EnsureUpdated(ctx, idx.URL, dir, cfg.Ref); err != nil {
+		return err
+	}
+	if err := indexmetadata.Verify(dir, trust, time.Now()); err != nil {
+		klog.V(1).Infof("Index %q failed verification, resetting to %s", idx.Name, prev)
+		if resetErr := gitutil.ResetHard(dir, prev); resetErr != nil {
+			klog.Warningf("failed to restore index %q to its previous version: %v", idx.Name, resetErr)
+		}
+		return errors.Wrap(err, "refusing index update that failed verification")
+	}
+	return indexmetadata.SaveTrust(paths.IndexTrustPath(idx.Name), trust)
+}
+
+// SetIndexRef pins the named git index to the branch, tag or commit ref, or
+// makes it track the default branch of its remote if ref is empty, and updates
+// the index to it. The previous ref is kept if the update fails.
+func SetIndexRef(paths environment.Paths, name, ref string) error {
+	backend, err := IndexBackend(paths, name)
+	if err != nil {
+		return err
+	}
+	if backend != BackendGit {
+		return errors.Errorf("refs are only supported for git indexes, not %s indexes", backend)
+	}
+	url, err := RemoteURL(paths, name)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadIndexConfig(paths, name)
+	if err != nil {
+		return err
+	}
+	prev := cfg.Ref
+	cfg.Ref = ref
+	if err := SaveIndexConfig(paths, name, cfg); err != nil {
+		return err
+	}
+	if err := UpdateIndex(context.Background(), paths, Index{Name: name, URL: url}); err != nil {
+		cfg.Ref = prev
+		if saveErr := SaveIndexConfig(paths, name, cfg); saveErr != nil {
+			klog.Warningf("failed to restore ref of index %q: %v", name, saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// bootstrapTrust establishes and stores the initial trust in the metadata of
+// the named index.
+func bootstrapTrust(paths environment.Paths, name string, rootKeys []string) error {
+	dir := paths.IndexPath(name)
+	trust, err := indexmetadata.Bootstrap(dir, rootKeys)
+	if err != nil {
+		return err
+	}
+	if err := indexmetadata.Verify(dir, trust, time.Now()); err != nil {
+		return err
+	}
+	return indexmetadata.SaveTrust(paths.IndexTrustPath(name), trust)
+}
+
 // DeleteIndex removes specified index name. If index does not exist, returns an error that can be tested by os.IsNotExist.
 func DeleteIndex(paths environment.Paths, name string) error {
 	dir := paths.IndexPath(name)
@@ -77,7 +240,15 @@ func DeleteIndex(paths environment.Paths, name string) error {
 		return err
 	}
 
-	return os.RemoveAll(dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for _, f := range []string{paths.IndexConfigPath(name), paths.IndexTrustPath(name), paths.IndexStatePath(name), paths.IndexCatalogPath(name)} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to remove index config")
+		}
+	}
+	return nil
 }
 
 // IsValidIndexName validates if an index name contains invalid characters
@@ -15,6 +15,7 @@
 package indexoperations
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -22,6 +23,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/gitutil"
 	"sigs.k8s.io/krew/internal/testutil"
 )
 
@@ -62,7 +64,7 @@ func TestAddIndexSuccess(t *testing.T) {
 	tmpDir.InitEmptyGitRepo(localRepo, "")
 
 	paths := environment.NewPaths(tmpDir.Root())
-	if err := AddIndex(paths, indexName, localRepo); err != nil {
+	if err := AddIndex(paths, indexName, localRepo, IndexConfig{}); err != nil {
 		t.Errorf("error adding index: %v", err)
 	}
 	gotIndexes, err := ListIndexes(paths)
@@ -85,7 +87,7 @@ func TestAddIndexFailure(t *testing.T) {
 
 	indexName := "foo"
 	paths := environment.NewPaths(tmpDir.Root())
-	if err := AddIndex(paths, indexName, tmpDir.Path("invalid/repo")); err == nil {
+	if err := AddIndex(paths, indexName, tmpDir.Path("invalid/repo"), IndexConfig{}); err == nil {
 		t.Error("expected error when adding index with invalid URL")
 	}
 
@@ -93,11 +95,11 @@ func TestAddIndexFailure(t *testing.T) {
 	tmpDir.InitEmptyGitRepo(tmpDir.Path("index/"+indexName), "")
 	tmpDir.InitEmptyGitRepo(localRepo, "")
 
-	if err := AddIndex(paths, indexName, localRepo); err == nil {
+	if err := AddIndex(paths, indexName, localRepo, IndexConfig{}); err == nil {
 		t.Error("expected error when adding an index that already exists")
 	}
 
-	if err := AddIndex(paths, "foo/bar", ""); err == nil {
+	if err := AddIndex(paths, "foo/bar", "", IndexConfig{}); err == nil {
 		t.Error("expected error with invalid index name")
 	}
 }
@@ -174,3 +176,104 @@ func TestIsValidIndexName(t *testing.T) {
 		})
 	}
 }
+
+func TestAddIndex_failedVerification(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	localRepo := tmpDir.Path("local/foo")
+	tmpDir.InitEmptyGitRepo(localRepo, "")
+
+	paths := environment.NewPaths(tmpDir.Root())
+	err := AddIndex(paths, "foo", localRepo, IndexConfig{VerifyMetadata: true})
+	if err == nil {
+		t.Fatal("expected error adding index without signed metadata")
+	}
+	if _, err := os.Stat(paths.IndexPath("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected index that failed verification to be removed, got: %v", err)
+	}
+	if _, err := os.Stat(paths.IndexConfigPath("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected no config for index that failed verification, got: %v", err)
+	}
+}
+
+func TestUpdateIndex_missingTrust(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	tmpDir.InitEmptyGitRepo(paths.IndexPath("foo"), "")
+	if err := SaveIndexConfig(paths, "foo", IndexConfig{VerifyMetadata: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateIndex(context.Background(), paths, Index{Name: "foo"}); err == nil {
+		t.Error("expected error updating verified index without trusted metadata")
+	}
+}
+
+// commitFile commits a file with the given content to the repository at dir
+// and returns the commit.
+func commitFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "file"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", content},
+	} {
+		if _, err := gitutil.Exec(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit, err := gitutil.HeadCommit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit
+}
+
+func TestAddIndex_ref(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	localRepo := tmpDir.Path("local/foo")
+	tmpDir.InitEmptyGitRepo(localRepo, "")
+	first := commitFile(t, localRepo, "v1")
+	if _, err := gitutil.Exec(localRepo, "tag", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	second := commitFile(t, localRepo, "v2")
+
+	if err := AddIndex(paths, "foo", localRepo, IndexConfig{Ref: "v1"}); err != nil {
+		t.Fatalf("AddIndex() failed: %v", err)
+	}
+	if got, _ := Revision(paths, "foo"); got != first {
+		t.Errorf("expected index pinned to tag at %s, got %s", first, got)
+	}
+	if err := UpdateIndex(context.Background(), paths, Index{Name: "foo", URL: localRepo}); err != nil {
+		t.Fatalf("UpdateIndex() failed: %v", err)
+	}
+	if got, _ := Revision(paths, "foo"); got != first {
+		t.Errorf("expected update to keep index pinned at %s, got %s", first, got)
+	}
+
+	if err := SetIndexRef(paths, "foo", ""); err != nil {
+		t.Fatalf("SetIndexRef() failed: %v", err)
+	}
+	if got, _ := Revision(paths, "foo"); got != second {
+		t.Errorf("expected index to track the default branch at %s, got %s", second, got)
+	}
+	if err := SetIndexRef(paths, "foo", first[:8]); err != nil {
+		t.Fatalf("SetIndexRef() with abbreviated commit failed: %v", err)
+	}
+	if got, _ := Revision(paths, "foo"); got != first {
+		t.Errorf("expected index at commit %s, got %s", first, got)
+	}
+
+	if err := SetIndexRef(paths, "foo", "missing"); err == nil {
+		t.Error("expected error setting missing ref")
+	}
+	cfg, err := LoadIndexConfig(paths, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Ref != first[:8] {
+		t.Errorf("expected previous ref to be kept after failed update, got %q", cfg.Ref)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/validation"
+	"sigs.k8s.io/krew/pkg/constants"
+)
+
+// PrioritizedIndexes returns the configured indexes, highest priority first.
+// Indexes with the same priority are ordered by name, except that the default
+// index comes first.
+func PrioritizedIndexes(paths environment.Paths) ([]Index, error) {
+	indexes, err := ListIndexes(paths)
+	if err != nil {
+		return nil, err
+	}
+	priorities := make(map[string]int, len(indexes))
+	for _, idx := range indexes {
+		cfg, err := LoadIndexConfig(paths, idx.Name)
+		if err != nil {
+			return nil, err
+		}
+		priorities[idx.Name] = cfg.Priority
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := indexes[i].Name, indexes[j].Name
+		if priorities[a] != priorities[b] {
+			return priorities[a] > priorities[b]
+		}
+		if (a == constants.DefaultIndexName) != (b == constants.DefaultIndexName) {
+			return a == constants.DefaultIndexName
+		}
+		return a < b
+	})
+	return indexes, nil
+}
+
+// PluginIndexes returns the names of the indexes that have a plugin with the
+// given name, highest priority first. A plugin name without an index refers
+// to the plugin in the first of them, and shadows the plugins in the others.
+func PluginIndexes(paths environment.Paths, name string) ([]string, error) {
+	if !validation.IsSafePluginName(name) {
+		return nil, nil
+	}
+	indexes, err := PrioritizedIndexes(paths)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, idx := range indexes {
+		_, err := os.Stat(filepath.Join(paths.IndexPluginsPath(idx.Name), name+constants.ManifestExtension))
+		if err == nil {
+			out = append(out, idx.Name)
+		} else if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "failed to look up plugin %q in index %q", name, idx.Name)
+		}
+	}
+	return out, nil
+}
+
+// SetIndexPriority changes the priority of the named index.
+func SetIndexPriority(paths environment.Paths, name string, priority int) error {
+	if _, err := os.Stat(paths.IndexPath(name)); err != nil {
+		return err
+	}
+	cfg, err := LoadIndexConfig(paths, name)
+	if err != nil {
+		return err
+	}
+	cfg.Priority = priority
+	return SaveIndexConfig(paths, name, cfg)
+}
+
+// SetImplicitIndex gives the named index a higher priority than all other
+// indexes, so that plugin names without an index refer to its plugins first.
+func SetImplicitIndex(paths environment.Paths, name string) error {
+	indexes, err := PrioritizedIndexes(paths)
+	if err != nil {
+		return err
+	}
+	priority := 0
+	for _, idx := range indexes {
+		if idx.Name == name {
+			continue
+		}
+		cfg, err := LoadIndexConfig(paths, idx.Name)
+		if err != nil {
+			return err
+		}
+		if cfg.Priority >= priority {
+			priority = cfg.Priority + 1
+		}
+	}
+	return SetIndexPriority(paths, name, priority)
+}
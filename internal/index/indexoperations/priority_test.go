@@ -0,0 +1,77 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/testutil"
+)
+
+func TestPluginIndexes(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	paths := environment.NewPaths(tmpDir.Root())
+	for _, name := range []string{"a", "default", "z"} {
+		tmpDir.InitEmptyGitRepo(paths.IndexPath(name), "https://example.com/"+name+".git")
+		tmpDir.WriteYAML("index/"+name+"/plugins/foo.yaml", testutil.NewPlugin().WithName("foo").V())
+	}
+	tmpDir.WriteYAML("index/z/plugins/bar.yaml", testutil.NewPlugin().WithName("bar").V())
+
+	got, err := PluginIndexes(paths, "foo")
+	if err != nil {
+		t.Fatalf("PluginIndexes() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"default", "a", "z"}, got); diff != "" {
+		t.Errorf("expected default index first among equal priorities: %s", diff)
+	}
+	got, err = PluginIndexes(paths, "bar")
+	if err != nil {
+		t.Fatalf("PluginIndexes() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"z"}, got); diff != "" {
+		t.Errorf("expected only index with the plugin: %s", diff)
+	}
+
+	if err := SetIndexPriority(paths, "z", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetIndexPriority(paths, "default", -1); err != nil {
+		t.Fatal(err)
+	}
+	got, err = PluginIndexes(paths, "foo")
+	if err != nil {
+		t.Fatalf("PluginIndexes() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"z", "a", "default"}, got); diff != "" {
+		t.Errorf("expected indexes ordered by priority: %s", diff)
+	}
+
+	if err := SetImplicitIndex(paths, "a"); err != nil {
+		t.Fatalf("SetImplicitIndex() failed: %v", err)
+	}
+	got, err = PluginIndexes(paths, "foo")
+	if err != nil {
+		t.Fatalf("PluginIndexes() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "z", "default"}, got); diff != "" {
+		t.Errorf("expected implicit index first: %s", diff)
+	}
+	if err := SetImplicitIndex(paths, "missing"); err == nil {
+		t.Error("expected error making a missing index implicit")
+	}
+}
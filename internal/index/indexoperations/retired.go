@@ -0,0 +1,90 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/validation"
+	"sigs.k8s.io/krew/pkg/constants"
+)
+
+// RetiredPlugin is a plugin that was removed from, renamed in or deprecated
+// by its index. Plugins that are still in the index are deprecated.
+type RetiredPlugin struct {
+	Name string `json:"name"`
+	// Replacement is the name of the plugin in the same index that replaces
+	// the retired plugin, if any.
+	Replacement string `json:"replacement,omitempty"`
+	// Reason is shown to users who have the plugin installed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// retiredPlugins is the format of the constants.RetiredPluginsFile of an
+// index.
+type retiredPlugins struct {
+	Plugins []RetiredPlugin `json:"plugins"`
+}
+
+// RetiredPlugins returns the retired plugins of the named index by name. An
+// index without a list of retired plugins has none.
+func RetiredPlugins(paths environment.Paths, name string) (map[string]RetiredPlugin, error) {
+	b, err := os.ReadFile(filepath.Join(paths.IndexPath(name), constants.RetiredPluginsFile))
+	if os.IsNotExist(err) {
+		return map[string]RetiredPlugin{}, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read retired plugins")
+	}
+	return parseRetiredPlugins(b)
+}
+
+func parseRetiredPlugins(b []byte) (map[string]RetiredPlugin, error) {
+	var list retiredPlugins
+	if err := yaml.Unmarshal(b, &list); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", constants.RetiredPluginsFile)
+	}
+	out := make(map[string]RetiredPlugin, len(list.Plugins))
+	for _, p := range list.Plugins {
+		if !validation.IsSafePluginName(p.Name) {
+			return nil, errors.Errorf("retired plugin has unsafe name %q", p.Name)
+		}
+		if p.Replacement != "" && (!validation.IsSafePluginName(p.Replacement) || p.Replacement == p.Name) {
+			return nil, errors.Errorf("retired plugin %q has invalid replacement %q", p.Name, p.Replacement)
+		}
+		out[p.Name] = p
+	}
+	return out, nil
+}
+
+// writeRetiredPlugins writes the retired plugins of a catalog as the list of
+// retired plugins of an index in dir.
+func writeRetiredPlugins(dir string, plugins []RetiredPlugin) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+	b, err := yaml.Marshal(retiredPlugins{Plugins: plugins})
+	if err != nil {
+		return errors.Wrap(err, "failed to convert retired plugins to yaml")
+	}
+	if _, err := parseRetiredPlugins(b); err != nil {
+		return errors.Wrap(err, "catalog contains invalid retired plugins")
+	}
+	return errors.Wrap(os.WriteFile(filepath.Join(dir, constants.RetiredPluginsFile), b, 0o644), "failed to write retired plugins")
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/testutil"
+)
+
+func TestRetiredPlugins(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]RetiredPlugin
+		wantErr  bool
+	}{
+		{
+			name:     "no retired plugins",
+			expected: map[string]RetiredPlugin{},
+		},
+		{
+			name: "retired plugins",
+			content: `plugins:
+- name: foo
+  replacement: bar
+  reason: renamed to match the upstream project
+- name: baz
+`,
+			expected: map[string]RetiredPlugin{
+				"foo": {Name: "foo", Replacement: "bar", Reason: "renamed to match the upstream project"},
+				"baz": {Name: "baz"},
+			},
+		},
+		{
+			name:    "unsafe name",
+			content: "plugins:\n- name: ../foo\n",
+			wantErr: true,
+		},
+		{
+			name:    "replaced by itself",
+			content: "plugins:\n- name: foo\n  replacement: foo\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			content: "plugins: foo",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+			paths := environment.NewPaths(tmpDir.Root())
+			tmpDir.Write("index/foo/plugins/.keep", nil)
+			if tt.content != "" {
+				tmpDir.Write("index/foo/retired.yaml", []byte(tt.content))
+			}
+
+			got, err := RetiredPlugins(paths, "foo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RetiredPlugins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("RetiredPlugins() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
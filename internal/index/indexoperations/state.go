@@ -0,0 +1,66 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexoperations
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/krew/internal/environment"
+)
+
+// indexState is the state of the local copy of an index.
+type indexState struct {
+	// LastUpdated is the time the index was last added or updated
+	// successfully.
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// LastUpdated returns the time the local copy of the named index was last
+// updated successfully. For indexes updated before this time was recorded,
+// the modification time of the index directory is returned.
+func LastUpdated(paths environment.Paths, name string) (time.Time, error) {
+	b, err := os.ReadFile(paths.IndexStatePath(name))
+	if os.IsNotExist(err) {
+		fi, err := os.Stat(paths.IndexPath(name))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return fi.ModTime(), nil
+	} else if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to read state of index %q", name)
+	}
+	var s indexState
+	if err := json.Unmarshal(b, &s); err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to parse state of index %q", name)
+	}
+	return s.LastUpdated, nil
+}
+
+func saveState(paths environment.Paths, name string, s indexState) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode index state")
+	}
+	path := paths.IndexStatePath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create index state directory")
+	}
+	return errors.Wrapf(os.WriteFile(path, b, 0o644), "failed to write state of index %q", name)
+}
@@ -16,6 +16,9 @@
 package validation
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/url"
 	"regexp"
 	"strings"
 
@@ -29,11 +32,13 @@ import (
 
 const (
 	sha256Pattern = `^[a-f0-9]{64}$`
+	sha512Pattern = `^[a-f0-9]{128}$`
 )
 
 var (
 	safePluginRegexp = regexp.MustCompile(`^[\w-]+$`)
 	validSHA256      = regexp.MustCompile(sha256Pattern)
+	validSHA512      = regexp.MustCompile(sha512Pattern)
 
 	// windowsForbidden is taken from  https://docs.microsoft.com/en-us/windows/desktop/FileIO/naming-a-file
 	windowsForbidden = []string{"CON", "PRN", "AUX", "NUL", "COM1", "COM2",
@@ -59,6 +64,7 @@ func isSupportedAPIVersion(apiVersion string) bool {
 }
 
 func isValidSHA256(s string) bool { return validSHA256.MatchString(s) }
+func isValidSHA512(s string) bool { return validSHA512.MatchString(s) }
 
 // ValidatePlugin checks for structural validity of the Plugin object with given
 // name.
@@ -104,15 +110,27 @@ func validatePlatform(p index.Platform) error {
 	if p.URI == "" {
 		return errors.New("`uri` has to be set")
 	}
-	if p.Sha256 == "" {
-		return errors.New("`sha256` sum has to be set")
+	if p.Sha256 == "" && p.Sha512 == "" {
+		return errors.New("`sha256` or `sha512` sum has to be set")
 	}
-	if !isValidSHA256(p.Sha256) {
+	if p.Sha256 != "" && !isValidSHA256(p.Sha256) {
 		return errors.Errorf("`sha256` value %s is not valid, must match pattern %s", p.Sha256, sha256Pattern)
 	}
+	if p.Sha512 != "" && !isValidSHA512(p.Sha512) {
+		return errors.Errorf("`sha512` value %s is not valid, must match pattern %s", p.Sha512, sha512Pattern)
+	}
+	if err := validateMirrors(p.URI, p.Mirrors); err != nil {
+		return errors.Wrap(err, "`mirrors` is invalid")
+	}
+	if err := validateSignature(p.Signature); err != nil {
+		return errors.Wrap(err, "`signature` is invalid")
+	}
 	if p.Bin == "" {
 		return errors.New("`bin` has to be set")
 	}
+	if p.Format != "" && p.Format != constants.BinaryFormat {
+		return errors.Errorf("`format` value %q is not supported, must be unset or %q", p.Format, constants.BinaryFormat)
+	}
 	if err := validateFiles(p.Files); err != nil {
 		return errors.Wrap(err, "`files` is invalid")
 	}
@@ -122,6 +140,57 @@ func validatePlatform(p index.Platform) error {
 	return nil
 }
 
+// validateSignature checks that the signature, if any, is well-formed and
+// names exactly one key.
+func validateSignature(sig *index.Signature) error {
+	if sig == nil {
+		return nil
+	}
+	if sig.Algorithm != "" && sig.Algorithm != constants.SignatureAlgorithmEd25519 {
+		return errors.Errorf("`algorithm` value %q is not supported, must be unset or %q", sig.Algorithm, constants.SignatureAlgorithmEd25519)
+	}
+	if (sig.PublicKey == "") == (sig.KeyRef == "") {
+		return errors.New("exactly one of `publicKey` or `keyRef` has to be set")
+	}
+	if sig.PublicKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(sig.PublicKey); err != nil || len(key) != ed25519.PublicKeySize {
+			return errors.New("`publicKey` has to be a base64-encoded ed25519 public key")
+		}
+	}
+	if value, err := base64.StdEncoding.DecodeString(sig.Value); err != nil || len(value) != ed25519.SignatureSize {
+		return errors.New("`value` has to be a base64-encoded ed25519 signature")
+	}
+	return nil
+}
+
+// validateMirrors checks that each mirror is a distinct absolute http(s) URL.
+func validateMirrors(uri string, mirrors []string) error {
+	if mirrors == nil {
+		return nil
+	}
+	if len(mirrors) == 0 {
+		return errors.New("`mirrors` has to be unspecified or non-empty")
+	}
+	seen := map[string]bool{uri: true}
+	for _, m := range mirrors {
+		u, err := url.Parse(m)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse mirror %q", m)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("mirror %q must use http or https scheme", m)
+		}
+		if u.Host == "" {
+			return errors.Errorf("mirror %q has no host", m)
+		}
+		if seen[m] {
+			return errors.Errorf("mirror %q is specified more than once", m)
+		}
+		seen[m] = true
+	}
+	return nil
+}
+
 func validateFiles(fops []index.FileOperation) error {
 	if fops == nil {
 		return nil
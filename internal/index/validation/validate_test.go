@@ -15,6 +15,8 @@
 package validation
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -207,6 +209,21 @@ func TestValidatePlatform(t *testing.T) {
 			platform: testutil.NewPlatform().WithSHA256("").V(),
 			wantErr:  true,
 		},
+		{
+			name:     "sha512 instead of sha256",
+			platform: testutil.NewPlatform().WithSHA256("").WithSHA512(strings.Repeat("a", 128)).V(),
+			wantErr:  false,
+		},
+		{
+			name:     "sha256 and sha512",
+			platform: testutil.NewPlatform().WithSHA512(strings.Repeat("a", 128)).V(),
+			wantErr:  false,
+		},
+		{
+			name:     "invalid sha512",
+			platform: testutil.NewPlatform().WithSHA512(strings.Repeat("a", 64)).V(),
+			wantErr:  true,
+		},
 		{
 			name:     "empty file operations",
 			platform: testutil.NewPlatform().WithFiles([]index.FileOperation{}).V(),
@@ -217,6 +234,26 @@ func TestValidatePlatform(t *testing.T) {
 			platform: testutil.NewPlatform().WithBin("").V(),
 			wantErr:  true,
 		},
+		{
+			name:     "with mirrors",
+			platform: testutil.NewPlatform().WithMirrors("https://mirror.example.com/foo.tar.gz").V(),
+			wantErr:  false,
+		},
+		{
+			name:     "binary format",
+			platform: testutil.NewPlatform().WithFormat(constants.BinaryFormat).V(),
+			wantErr:  false,
+		},
+		{
+			name:     "unsupported format",
+			platform: testutil.NewPlatform().WithFormat("rar").V(),
+			wantErr:  true,
+		},
+		{
+			name:     "invalid mirror",
+			platform: testutil.NewPlatform().WithMirrors("ftp://mirror.example.com/foo.tar.gz").V(),
+			wantErr:  true,
+		},
 		{
 			name: "invalid platform selector",
 			platform: testutil.NewPlatform().WithSelector(&metav1.LabelSelector{
@@ -341,3 +378,118 @@ func Test_validateFiles(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateMirrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mirrors []string
+		wantErr bool
+	}{
+		{
+			name:    "unspecified mirrors",
+			mirrors: nil,
+			wantErr: false,
+		},
+		{
+			name:    "empty mirrors",
+			mirrors: []string{},
+			wantErr: true,
+		},
+		{
+			name:    "http and https mirrors",
+			mirrors: []string{"http://a.example.com/foo.zip", "https://b.example.com/foo.zip"},
+			wantErr: false,
+		},
+		{
+			name:    "relative mirror",
+			mirrors: []string{"foo.zip"},
+			wantErr: true,
+		},
+		{
+			name:    "mirror without host",
+			mirrors: []string{"https:///foo.zip"},
+			wantErr: true,
+		},
+		{
+			name:    "mirror with unsupported scheme",
+			mirrors: []string{"file:///tmp/foo.zip"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate mirror",
+			mirrors: []string{"https://a.example.com/foo.zip", "https://a.example.com/foo.zip"},
+			wantErr: true,
+		},
+		{
+			name:    "mirror same as uri",
+			mirrors: []string{"https://example.com/foo.zip"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMirrors("https://example.com/foo.zip", tt.mirrors); (err != nil) != tt.wantErr {
+				t.Errorf("validateMirrors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateSignature(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	value := base64.StdEncoding.EncodeToString(make([]byte, 64))
+	tests := []struct {
+		name    string
+		sig     *index.Signature
+		wantErr bool
+	}{
+		{
+			name: "no signature",
+		},
+		{
+			name: "inline public key",
+			sig:  &index.Signature{PublicKey: key, Value: value},
+		},
+		{
+			name: "key reference with algorithm",
+			sig:  &index.Signature{Algorithm: "ed25519", KeyRef: "publisher", Value: value},
+		},
+		{
+			name:    "unsupported algorithm",
+			sig:     &index.Signature{Algorithm: "rsa", PublicKey: key, Value: value},
+			wantErr: true,
+		},
+		{
+			name:    "no key",
+			sig:     &index.Signature{Value: value},
+			wantErr: true,
+		},
+		{
+			name:    "both public key and key reference",
+			sig:     &index.Signature{PublicKey: key, KeyRef: "publisher", Value: value},
+			wantErr: true,
+		},
+		{
+			name:    "malformed public key",
+			sig:     &index.Signature{PublicKey: "Zm9v", Value: value},
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			sig:     &index.Signature{PublicKey: key},
+			wantErr: true,
+		},
+		{
+			name:    "value of wrong size",
+			sig:     &index.Signature{PublicKey: key, Value: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 10)))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSignature(tt.sig); (err != nil) != tt.wantErr {
+				t.Errorf("validateSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
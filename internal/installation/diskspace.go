@@ -0,0 +1,112 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/download"
+)
+
+// archiveExpansion is the ratio of the size of the extracted files to the
+// size of a plugin archive that free disk space is checked for.
+const archiveExpansion = 4
+
+// errFreeSpaceUnsupported is returned by freeSpace on platforms where the
+// available disk space can't be determined.
+var errFreeSpaceUnsupported = errors.New("checking free disk space is not supported on this platform")
+
+// requiredFreeSpace returns the free disk space needed to extract and install
+// a plugin archive of the given size: the archive size times
+// archiveExpansion, but no more than the extracted files are allowed to take
+// up. The KREW_MIN_FREE_SPACE environment variable overrides it.
+func requiredFreeSpace(archiveSize int64) (int64, error) {
+	if os.Getenv("KREW_MIN_FREE_SPACE") != "" {
+		return download.SizeFromEnv("KREW_MIN_FREE_SPACE", 0)
+	}
+	maxSize, err := download.MaxExtractSize()
+	if err != nil {
+		return 0, err
+	}
+	required := int64(math.MaxInt64)
+	if archiveSize < math.MaxInt64/archiveExpansion {
+		required = archiveSize * archiveExpansion
+	}
+	if maxSize > 0 && required > maxSize {
+		required = maxSize
+	}
+	return required, nil
+}
+
+// checkFreeSpace returns an error if a filesystem containing any of the
+// given directories has less free space than required to extract an archive
+// of the given size. Directories need not exist yet.
+func checkFreeSpace(archiveSize int64, dirs ...string) error {
+	required, err := requiredFreeSpace(archiveSize)
+	if err != nil {
+		return err
+	}
+	if required == 0 {
+		return nil
+	}
+	for _, dir := range dirs {
+		dir = existingAncestor(dir)
+		avail, err := freeSpace(dir)
+		if err != nil {
+			klog.V(2).Infof("Skipping free disk space check for %q: %v", dir, err)
+			continue
+		}
+		klog.V(3).Infof("Free disk space in %q: %d bytes, %d required", dir, avail, required)
+		if avail < uint64(required) {
+			return errors.Errorf("not enough free disk space in %q: %s available, %s required (set KREW_MIN_FREE_SPACE to change)",
+				dir, resource.NewQuantity(int64(avail), resource.BinarySI), resource.NewQuantity(required, resource.BinarySI))
+		}
+	}
+	return nil
+}
+
+// checkingFreeSpace returns an Extractor that checks the free disk space in
+// the given directories for the downloaded archive before extracting it with
+// ex.
+func checkingFreeSpace(ex download.Extractor, dirs ...string) download.Extractor {
+	return func(targetDir string, at io.ReaderAt, size int64) error {
+		if err := checkFreeSpace(size, append([]string{targetDir}, dirs...)...); err != nil {
+			return err
+		}
+		return ex(targetDir, at, size)
+	}
+}
+
+// existingAncestor returns the closest parent of dir, including dir itself,
+// that exists.
+func existingAncestor(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
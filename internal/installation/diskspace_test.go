@@ -0,0 +1,109 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_requiredFreeSpace(t *testing.T) {
+	tests := []struct {
+		name         string
+		minFreeSpace string
+		maxExtract   string
+		archiveSize  int64
+		expected     int64
+		wantErr      bool
+	}{
+		{
+			name:        "archive size times expansion",
+			archiveSize: 10 << 20,
+			expected:    40 << 20,
+		},
+		{
+			name:        "capped at the extraction limit",
+			maxExtract:  "20Mi",
+			archiveSize: 10 << 20,
+			expected:    20 << 20,
+		},
+		{
+			name:        "unlimited extraction",
+			maxExtract:  "0",
+			archiveSize: 1 << 40,
+			expected:    4 << 40,
+		},
+		{
+			name:         "overridden",
+			minFreeSpace: "1Ki",
+			archiveSize:  10 << 20,
+			expected:     1 << 10,
+		},
+		{
+			name:         "disabled",
+			minFreeSpace: "0",
+			archiveSize:  10 << 20,
+			expected:     0,
+		},
+		{
+			name:         "invalid override",
+			minFreeSpace: "invalid",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KREW_MIN_FREE_SPACE", tt.minFreeSpace)
+			t.Setenv("KREW_MAX_EXTRACT_SIZE", tt.maxExtract)
+			got, err := requiredFreeSpace(tt.archiveSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requiredFreeSpace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("requiredFreeSpace() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_checkFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	notExisting := filepath.Join(dir, "a", "b")
+
+	if err := checkFreeSpace(1<<10, dir, notExisting); err != nil {
+		t.Fatalf("checkFreeSpace() of small archive failed: %v", err)
+	}
+
+	if _, err := freeSpace(dir); err == errFreeSpaceUnsupported {
+		t.Skipf("free disk space can't be determined on %s", runtime.GOOS)
+	}
+	t.Setenv("KREW_MAX_EXTRACT_SIZE", "0")
+	err := checkFreeSpace(1<<60, notExisting)
+	if err == nil || !strings.Contains(err.Error(), "not enough free disk space") {
+		t.Fatalf("checkFreeSpace() error = %v, want not enough free disk space", err)
+	}
+}
+
+func Test_existingAncestor(t *testing.T) {
+	dir := t.TempDir()
+	if got := existingAncestor(filepath.Join(dir, "a", "b")); got != dir {
+		t.Errorf("existingAncestor() = %q, want %q", got, dir)
+	}
+	if got := existingAncestor(dir); got != dir {
+		t.Errorf("existingAncestor() = %q, want %q", got, dir)
+	}
+}
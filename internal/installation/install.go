@@ -26,6 +26,7 @@ import (
 
 	"sigs.k8s.io/krew/internal/download"
 	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/installation/receipt"
 	"sigs.k8s.io/krew/internal/pathutil"
 	"sigs.k8s.io/krew/pkg/constants"
@@ -45,6 +46,8 @@ type installOperation struct {
 
 	installDir string
 	binDir     string
+
+	indexConfig indexoperations.IndexConfig
 }
 
 // Plugin lifecycle errors
@@ -73,31 +76,50 @@ func Install(p environment.Paths, plugin index.Plugin, indexName string, opts In
 	if !ok {
 		return errors.Errorf("plugin %q does not offer installation for this platform", plugin.Name)
 	}
+	indexConfig, err := indexoperations.LoadIndexConfig(p, indexName)
+	if err != nil {
+		return err
+	}
 
 	// The actual install should be the last action so that a failure during receipt
 	// saving does not result in an installed plugin without receipt.
 	klog.V(3).Infof("Install plugin %s at version=%s", plugin.Name, plugin.Spec.Version)
-	if err := install(installOperation{
+	uri, err := install(installOperation{
 		pluginName: plugin.Name,
 		platform:   candidate,
 
 		binDir:     p.BinPath(),
 		installDir: p.PluginVersionInstallPath(plugin.Name, plugin.Spec.Version),
-	}, opts); err != nil {
+
+		indexConfig: indexConfig,
+	}, opts)
+	if err != nil {
 		return errors.Wrap(err, "install failed")
 	}
 
 	klog.V(3).Infof("Storing install receipt for plugin %s", plugin.Name)
-	err = receipt.Store(receipt.New(plugin, indexName, metav1.Now()), p.PluginInstallReceiptPath(plugin.Name))
+	r, err := newReceipt(p, plugin, indexName, candidate, uri, metav1.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to create installation receipt")
+	}
+	err = receipt.Store(r, p.PluginInstallReceiptPath(plugin.Name))
 	return errors.Wrap(err, "installation receipt could not be stored, uninstall may fail")
 }
 
-func install(op installOperation, opts InstallOpts) error {
+// install downloads and installs the plugin described by op, and returns the
+// uri the plugin archive was downloaded from.
+func install(op installOperation, opts InstallOpts) (string, error) {
+	for _, uri := range platformURIs(op.platform) {
+		if err := op.indexConfig.CheckURI(uri); err != nil {
+			return "", err
+		}
+	}
+
 	// Download and extract
 	klog.V(3).Infof("Creating download staging directory")
 	downloadStagingDir, err := os.MkdirTemp("", "krew-downloads")
 	if err != nil {
-		return errors.Wrapf(err, "could not create staging dir %q", downloadStagingDir)
+		return "", errors.Wrapf(err, "could not create staging dir %q", downloadStagingDir)
 	}
 	klog.V(3).Infof("Successfully created download staging directory %q", downloadStagingDir)
 	defer func() {
@@ -106,29 +128,30 @@ func install(op installOperation, opts InstallOpts) error {
 			klog.Warningf("failed to clean up download staging directory: %s", err)
 		}
 	}()
-	if err := downloadAndExtract(downloadStagingDir, op.platform.URI, op.platform.Sha256, opts.ArchiveFileOverride, opts.EnableNetrc, opts.NetrcFile); err != nil {
-		return errors.Wrap(err, "failed to unpack into staging dir")
+	uri, err := downloadAndExtract(downloadStagingDir, op.installDir, op.platform, op.indexConfig, opts)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unpack into staging dir")
 	}
 
 	applyDefaults(&op.platform)
 	if err := moveToInstallDir(downloadStagingDir, op.installDir, op.platform.Files); err != nil {
-		return errors.Wrap(err, "failed while moving files to the installation directory")
+		return "", errors.Wrap(err, "failed while moving files to the installation directory")
 	}
 
 	subPathAbs, err := filepath.Abs(op.installDir)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get the absolute fullPath of %q", op.installDir)
+		return "", errors.Wrapf(err, "failed to get the absolute fullPath of %q", op.installDir)
 	}
 	fullPath := filepath.Join(op.installDir, filepath.FromSlash(op.platform.Bin))
 	pathAbs, err := filepath.Abs(fullPath)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get the absolute fullPath of %q", fullPath)
+		return "", errors.Wrapf(err, "failed to get the absolute fullPath of %q", fullPath)
 	}
 	if _, ok := pathutil.IsSubPath(subPathAbs, pathAbs); !ok {
-		return errors.Wrapf(err, "the fullPath %q does not extend the sub-fullPath %q", fullPath, op.installDir)
+		return "", errors.Wrapf(err, "the fullPath %q does not extend the sub-fullPath %q", fullPath, op.installDir)
 	}
 	err = createOrUpdateLink(op.binDir, fullPath, op.pluginName)
-	return errors.Wrap(err, "failed to link installed plugin")
+	return uri, errors.Wrap(err, "failed to link installed plugin")
 }
 
 func applyDefaults(platform *index.Platform) {
@@ -138,21 +161,46 @@ func applyDefaults(platform *index.Platform) {
 	}
 }
 
-// downloadAndExtract downloads the specified archive uri (or uses the provided overrideFile, if a non-empty value)
-// while validating its checksum with the provided sha256sum, and extracts its contents to extractDir that must be.
-// created.
-func downloadAndExtract(extractDir, uri, sha256sum, overrideFile string, enableNetrc bool, netrcFile string) error {
+// downloadAndExtract downloads the archive of the platform from the first of
+// its uri and mirrors that passes verification (or uses the ArchiveFileOverride
+// in opts, if a non-empty value) while validating its checksum and signature
+// according to cfg, and extracts its contents to extractDir that must be
+// created, if there is enough free disk space for them there and in
+// installDir. It returns the uri that was used, or an empty uri if the
+// archive was read from ArchiveFileOverride.
+func downloadAndExtract(extractDir, installDir string, platform index.Platform, cfg indexoperations.IndexConfig, opts InstallOpts) (string, error) {
 	var fetcher download.Fetcher = download.HTTPFetcher{
-		EnableNetrc: enableNetrc,
-		NetrcFile:   netrcFile,
+		EnableNetrc:   opts.EnableNetrc,
+		NetrcFile:     opts.NetrcFile,
+		CheckRedirect: cfg.CheckURI,
 	}
-	if overrideFile != "" {
-		fetcher = download.NewFileFetcher(overrideFile)
+	uris := platformURIs(platform)
+	if opts.ArchiveFileOverride != "" {
+		fetcher = download.NewFileFetcher(opts.ArchiveFileOverride)
+		uris = uris[:1]
 	}
 
-	verifier := download.NewSha256Verifier(sha256sum)
-	err := download.NewDownloader(verifier, fetcher).Get(uri, extractDir)
-	return errors.Wrap(err, "failed to unpack the plugin archive")
+	extract := download.ExtractArchive
+	if platform.Format == constants.BinaryFormat {
+		extract = download.BinaryExtractor(platform.Bin)
+	}
+
+	newVerifier, err := newPlatformVerifier(platform, cfg)
+	if err != nil {
+		return "", err
+	}
+	uri, err := download.GetFirst(uris, extractDir, newVerifier, fetcher, checkingFreeSpace(extract, installDir))
+	if opts.ArchiveFileOverride != "" {
+		// nothing was downloaded from the uri of the platform
+		uri = ""
+	}
+	return uri, errors.Wrap(err, "failed to unpack the plugin archive")
+}
+
+// platformURIs returns the uri and the mirrors of the platform, in the order
+// they are tried.
+func platformURIs(platform index.Platform) []string {
+	return append([]string{platform.URI}, platform.Mirrors...)
 }
 
 // Uninstall will uninstall a plugin.
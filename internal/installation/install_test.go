@@ -27,7 +27,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/installation/receipt"
 	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/constants"
 	"sigs.k8s.io/krew/pkg/index"
 )
 
@@ -223,9 +226,14 @@ func Test_downloadAndExtract(t *testing.T) {
 	url := server.URL + "/test-flat-hierarchy.tar.gz"
 	checksum := "433b9e0b6cb9f064548f451150799daadcc70a3496953490c5148c8e550d2f4e"
 
-	if err := downloadAndExtract(tmpDir.Root(), url, checksum, "", false, ""); err != nil {
+	platform := testutil.NewPlatform().WithURI(server.URL + "/not-found.tar.gz").WithMirrors(url).WithSHA256(checksum).V()
+	uri, err := downloadAndExtract(tmpDir.Root(), tmpDir.Path("install"), platform, indexoperations.IndexConfig{}, InstallOpts{})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if uri != url {
+		t.Errorf("downloadAndExtract() used %q, expected fallback to %q", uri, url)
+	}
 	files, err := os.ReadDir(tmpDir.Root())
 	if err != nil {
 		t.Fatal(err)
@@ -241,9 +249,14 @@ func Test_downloadAndExtract_fileOverride(t *testing.T) {
 	testFile := filepath.Join(testdataPath(t), "..", "..", "download", "testdata", "test-flat-hierarchy.tar.gz")
 	checksum := "433b9e0b6cb9f064548f451150799daadcc70a3496953490c5148c8e550d2f4e"
 
-	if err := downloadAndExtract(tmpDir.Root(), "", checksum, testFile, false, ""); err != nil {
+	platform := testutil.NewPlatform().WithURI("https://example.com/foo.tar.gz").WithSHA256(checksum).V()
+	uri, err := downloadAndExtract(tmpDir.Root(), tmpDir.Path("install"), platform, indexoperations.IndexConfig{}, InstallOpts{ArchiveFileOverride: testFile})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if uri != "" {
+		t.Errorf("downloadAndExtract() = %q, expected no uri for archive read from a file", uri)
+	}
 	files, err := os.ReadDir(tmpDir.Root())
 	if err != nil {
 		t.Fatal(err)
@@ -253,6 +266,21 @@ func Test_downloadAndExtract_fileOverride(t *testing.T) {
 	}
 }
 
+func Test_downloadAndExtract_binaryFormat(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+
+	testFile := filepath.Join(testdataPath(t), "..", "..", "download", "testdata", "bash-ascii-file")
+	checksum := "5771dac4de43ae6f8b5d301bb739eabdc1402ab557242974893b2f8352cf458b"
+
+	platform := testutil.NewPlatform().WithFormat(constants.BinaryFormat).WithBin("kubectl-foo").WithSHA256(checksum).V()
+	if _, err := downloadAndExtract(tmpDir.Root(), tmpDir.Path("install"), platform, indexoperations.IndexConfig{}, InstallOpts{ArchiveFileOverride: testFile}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmpDir.Path("kubectl-foo")); err != nil {
+		t.Fatalf("binary not found in the extract output directory: %v", err)
+	}
+}
+
 func Test_applyDefaults(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -325,3 +353,88 @@ func TestCleanupStaleKrewInstallations(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func Test_install_checksDownloadPolicy(t *testing.T) {
+	cfg := indexoperations.IndexConfig{
+		AllowedSchemes: []string{"https"},
+		AllowedHosts:   []string{"artifacts.example.com"},
+	}
+	tests := []struct {
+		name     string
+		platform index.Platform
+	}{
+		{
+			name:     "disallowed host",
+			platform: testutil.NewPlatform().WithURI("https://evil.example.com/foo.tar.gz").V(),
+		},
+		{
+			name:     "disallowed scheme",
+			platform: testutil.NewPlatform().WithURI("http://artifacts.example.com/foo.tar.gz").V(),
+		},
+		{
+			name: "disallowed mirror",
+			platform: testutil.NewPlatform().WithURI("https://artifacts.example.com/foo.tar.gz").
+				WithMirrors("https://evil.example.com/foo.tar.gz").V(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+			_, err := install(installOperation{
+				pluginName:  "foo",
+				platform:    tt.platform,
+				installDir:  tmpDir.Path("store/foo/v1"),
+				binDir:      tmpDir.Path("bin"),
+				indexConfig: cfg,
+			}, InstallOpts{})
+			if err == nil || !strings.Contains(err.Error(), "not allowed by the index") {
+				t.Fatalf("install() error = %v, expected download policy error", err)
+			}
+		})
+	}
+}
+
+func TestReinstall(t *testing.T) {
+	t.Setenv("KREW_OS", "linux")
+	t.Setenv("KREW_ARCH", "amd64")
+	tmpDir := testutil.NewTempDir(t)
+	p := environment.NewPaths(tmpDir.Root())
+	if err := os.MkdirAll(p.BinPath(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	testFile := filepath.Join(testdataPath(t), "..", "..", "download", "testdata", "bash-ascii-file")
+	checksum := "5771dac4de43ae6f8b5d301bb739eabdc1402ab557242974893b2f8352cf458b"
+	platform := testutil.NewPlatform().WithFormat(constants.BinaryFormat).WithBin("kubectl-foo").WithFiles(nil).WithSHA256(checksum).V()
+	plugin := testutil.NewPlugin().WithName("foo").WithVersion("v1.0.0").WithPlatforms(platform).V()
+
+	if err := Reinstall(p, plugin, "detached", InstallOpts{ArchiveFileOverride: testFile}); err != ErrIsNotInstalled {
+		t.Fatalf("Reinstall() of plugin that is not installed = %v, expected %v", err, ErrIsNotInstalled)
+	}
+
+	installed := testutil.NewReceipt().WithPlugin(plugin).
+		WithStatus(index.ReceiptStatus{Source: index.SourceIndex{Name: "detached", Commit: "abc"}}).V()
+	tmpDir.WriteYAML("receipts/foo.yaml", installed)
+	tmpDir.Write("store/foo/v1.0.0/kubectl-foo", []byte("corrupted"))
+
+	if err := Reinstall(p, plugin, "detached", InstallOpts{ArchiveFileOverride: testFile}); err != nil {
+		t.Fatalf("Reinstall() failed: %v", err)
+	}
+	r, err := receipt.Load(p.PluginInstallReceiptPath("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status.Source.Commit != "abc" {
+		t.Errorf("expected index commit of unchanged manifest to be kept, got %q", r.Status.Source.Commit)
+	}
+	problems, err := VerifyInstallation(p, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) > 0 {
+		t.Errorf("expected reinstalled plugin to pass verification, got %v", problems)
+	}
+	if got, err := fileSha256(filepath.Join(p.PluginVersionInstallPath("foo", "v1.0.0"), "kubectl-foo")); err != nil || got != checksum {
+		t.Errorf("expected plugin binary to be restored, got sha256 %q (err=%v)", got, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// VerifyInstallation compares the installed files of the plugin in the
+// receipt, and the link to its executable, with what was recorded when it was
+// installed. It returns a description of each difference.
+func VerifyInstallation(p environment.Paths, r index.Receipt) ([]string, error) {
+	if len(r.Status.Files) == 0 {
+		return []string{"receipt does not list the installed files"}, nil
+	}
+	dir := p.PluginVersionInstallPath(r.Name, r.Spec.Version)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return []string{fmt.Sprintf("installation directory %q is missing", dir)}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to check installation directory %q", dir)
+	}
+	actual, err := listInstalledFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	found := make(map[string]index.InstalledFile, len(actual))
+	for _, f := range actual {
+		found[f.Path] = f
+	}
+	for _, want := range r.Status.Files {
+		got, ok := found[want.Path]
+		delete(found, want.Path)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("file %q is missing", want.Path))
+		case want.Link == "" && got.Link != "":
+			problems = append(problems, fmt.Sprintf("file %q was replaced with a link to %q", want.Path, got.Link))
+		case want.Link != "" && got.Link == "":
+			problems = append(problems, fmt.Sprintf("link %q was replaced with a file", want.Path))
+		case want.Link != got.Link:
+			problems = append(problems, fmt.Sprintf("link %q points to %q instead of %q", want.Path, got.Link, want.Link))
+		case want.Sha256 != got.Sha256:
+			problems = append(problems, fmt.Sprintf("file %q was modified", want.Path))
+		}
+	}
+	for _, f := range actual {
+		if _, ok := found[f.Path]; ok {
+			problems = append(problems, fmt.Sprintf("file %q was added", f.Path))
+		}
+	}
+
+	platform, ok, err := receiptPlatform(r)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return append(problems, "receipt does not contain the installed platform"), nil
+	}
+	link := filepath.Join(p.BinPath(), pluginNameToBin(r.Name, IsWindows()))
+	want := filepath.Join(dir, filepath.FromSlash(platform.Bin))
+	if got, err := os.Readlink(link); os.IsNotExist(err) {
+		problems = append(problems, fmt.Sprintf("link %q is missing", link))
+	} else if err != nil {
+		problems = append(problems, fmt.Sprintf("%q is not a link: %v", link, err))
+	} else if got != want {
+		problems = append(problems, fmt.Sprintf("link %q points to %q instead of %q", link, got, want))
+	}
+	return problems, nil
+}
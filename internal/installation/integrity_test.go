@@ -0,0 +1,138 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func TestVerifyInstallation(t *testing.T) {
+	t.Setenv("KREW_OS", "linux")
+	platform := testutil.NewPlatform().V()
+	platform.Bin = "kubectl-foo"
+	plugin := testutil.NewPlugin().WithName("foo").WithVersion("v1.0.0").WithPlatforms(platform).V()
+
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, tmpDir *testutil.TempDir, p environment.Paths)
+		want   func(p environment.Paths) []string
+	}{
+		{
+			name:   "unchanged",
+			tamper: func(*testing.T, *testutil.TempDir, environment.Paths) {},
+		},
+		{
+			name: "modified, missing and added files",
+			tamper: func(t *testing.T, tmpDir *testutil.TempDir, _ environment.Paths) {
+				tmpDir.Write("store/foo/v1.0.0/kubectl-foo", []byte("evil"))
+				tmpDir.Write("store/foo/v1.0.0/extra", []byte("evil"))
+				if err := os.Remove(tmpDir.Path("store/foo/v1.0.0/LICENSE")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: func(environment.Paths) []string {
+				return []string{
+					`file "LICENSE" is missing`,
+					`file "kubectl-foo" was modified`,
+					`file "extra" was added`,
+				}
+			},
+		},
+		{
+			name: "redirected bin link",
+			tamper: func(t *testing.T, tmpDir *testutil.TempDir, p environment.Paths) {
+				tmpDir.Write("evil", []byte("evil"))
+				link := filepath.Join(p.BinPath(), "kubectl-foo")
+				if err := os.Remove(link); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(tmpDir.Path("evil"), link); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: func(p environment.Paths) []string {
+				return []string{fmt.Sprintf("link %q points to %q instead of %q",
+					filepath.Join(p.BinPath(), "kubectl-foo"),
+					filepath.Join(p.BasePath(), "evil"),
+					filepath.Join(p.PluginVersionInstallPath("foo", "v1.0.0"), "kubectl-foo"))}
+			},
+		},
+		{
+			name: "missing installation directory",
+			tamper: func(t *testing.T, tmpDir *testutil.TempDir, _ environment.Paths) {
+				if err := os.RemoveAll(tmpDir.Path("store/foo/v1.0.0")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: func(p environment.Paths) []string {
+				return []string{fmt.Sprintf("installation directory %q is missing", p.PluginVersionInstallPath("foo", "v1.0.0"))}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+			p := environment.NewPaths(tmpDir.Root())
+			tmpDir.Write("store/foo/v1.0.0/kubectl-foo", []byte("hello"))
+			tmpDir.Write("store/foo/v1.0.0/LICENSE", []byte("world"))
+			if err := os.MkdirAll(p.BinPath(), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := createOrUpdateLink(p.BinPath(), tmpDir.Path("store/foo/v1.0.0/kubectl-foo"), "foo"); err != nil {
+				t.Fatal(err)
+			}
+			r, err := newReceipt(p, plugin, "detached", platform, platform.URI, metav1.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.tamper(t, tmpDir, p)
+			got, err := VerifyInstallation(p, r)
+			if err != nil {
+				t.Fatalf("VerifyInstallation() failed: %v", err)
+			}
+			var want []string
+			if tt.want != nil {
+				want = tt.want(p)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("VerifyInstallation() mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestVerifyInstallation_oldReceipt(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	p := environment.NewPaths(tmpDir.Root())
+	r := testutil.NewReceipt().WithPlugin(testutil.NewPlugin().V()).WithStatus(index.ReceiptStatus{}).V()
+	got, err := VerifyInstallation(p, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected one problem for receipt without files, got %v", got)
+	}
+}
@@ -128,6 +128,9 @@ func moveFiles(fromDir, toDir string, fo index.FileOperation) error {
 	}
 
 	for _, m := range moves {
+		if err := checkMoveSource(fromDir, m.from); err != nil {
+			return err
+		}
 		klog.V(2).Infof("Move file from %q to %q", m.from, m.to)
 		if err := os.MkdirAll(filepath.Dir(m.to), 0o755); err != nil {
 			return errors.Wrapf(err, "failed to create move path %q", filepath.Dir(m.to))
@@ -141,6 +144,24 @@ func moveFiles(fromDir, toDir string, fo index.FileOperation) error {
 	return nil
 }
 
+// checkMoveSource verifies that the directory containing from does not resolve
+// to a location outside of fromDir through symbolic links. The file itself may
+// be a link, which is moved as is.
+func checkMoveSource(fromDir, from string) error {
+	realFromDir, err := filepath.EvalSymlinks(fromDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to evaluate links in %q", fromDir)
+	}
+	realDir, err := filepath.EvalSymlinks(filepath.Dir(from))
+	if err != nil {
+		return errors.Wrapf(err, "failed to evaluate links in %q", filepath.Dir(from))
+	}
+	if _, ok := pathutil.IsSubPath(realFromDir, realDir); !ok {
+		return errors.Errorf("can't move %q, it resolves to %q which is outside of %q", from, realDir, fromDir)
+	}
+	return nil
+}
+
 func moveAllFiles(fromDir, toDir string, fos []index.FileOperation) error {
 	for _, fo := range fos {
 		if err := moveFiles(fromDir, toDir, fo); err != nil {
@@ -173,6 +194,9 @@ func moveToInstallDir(srcDir, installDir string, fos []index.FileOperation) erro
 	if err = moveAllFiles(srcDir, tmp, fos); err != nil {
 		return errors.Wrap(err, "failed to move files")
 	}
+	if err := pathutil.CheckSymlinks(tmp); err != nil {
+		return errors.Wrap(err, "moved files contain a link pointing outside of the installation directory")
+	}
 
 	klog.V(2).Infof("Move directory %q to %q", tmp, installDir)
 	if err = renameOrCopy(tmp, installDir); err != nil {
@@ -210,14 +234,22 @@ func renameOrCopy(from, to string) error {
 	return err
 }
 
-// copyTree copies files or directories, recursively.
+// copyTree copies files or directories, recursively. Symbolic links are copied
+// as links, and not followed.
 func copyTree(from, to string) (err error) {
 	return filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		newPath, _ := pathutil.ReplaceBase(path, from, to)
-		if info.IsDir() {
+		if info.Mode()&os.ModeSymlink != 0 {
+			// copy the link itself rather than the file it points to
+			klog.V(4).Infof("Copying symbolic link %q", newPath)
+			var target string
+			if target, err = os.Readlink(path); err == nil {
+				err = os.Symlink(target, newPath)
+			}
+		} else if info.IsDir() {
 			klog.V(4).Infof("Creating new dir %q", newPath)
 			err = os.MkdirAll(newPath, info.Mode())
 		} else {
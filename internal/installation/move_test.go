@@ -19,6 +19,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"testing"
 
 	"sigs.k8s.io/krew/internal/testutil"
@@ -237,3 +238,48 @@ func Test_moveOrCopyDir_removesExistingTarget(t *testing.T) {
 	}
 
 }
+
+func Test_copyTree_copiesLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires extra privileges on windows")
+	}
+	srcDir := testutil.NewTempDir(t)
+	srcDir.Write("libexec/tool", []byte("tool"))
+	if err := os.Mkdir(srcDir.Path("bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.FromSlash("../libexec/tool"), srcDir.Path("bin/tool")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := testutil.NewTempDir(t).Path("copy")
+	if err := copyTree(srcDir.Root(), dst); err != nil {
+		t.Fatalf("copyTree() failed: %+v", err)
+	}
+
+	target, err := os.Readlink(filepath.Join(dst, "bin", "tool"))
+	if err != nil {
+		t.Fatalf("expected a symbolic link to be copied: %v", err)
+	}
+	if target != filepath.FromSlash("../libexec/tool") {
+		t.Errorf("copied link points to %q, expected it to be unchanged", target)
+	}
+}
+
+func Test_moveToInstallDir_rejectsEscapingLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires extra privileges on windows")
+	}
+	srcDir := testutil.NewTempDir(t)
+	srcDir.Write("a/b/tool", []byte("tool"))
+	// the link resolves within srcDir, but not after moving it to the root
+	if err := os.Symlink(filepath.FromSlash("../../a/b/tool"), srcDir.Path("a/b/link")); err != nil {
+		t.Fatal(err)
+	}
+
+	installDir := testutil.NewTempDir(t).Path("plugin/v1")
+	err := moveToInstallDir(srcDir.Root(), installDir, []index.FileOperation{{From: "a/b/link", To: "."}})
+	if err == nil {
+		t.Fatal("expected moveToInstallDir() to fail for a link pointing outside of the installation directory")
+	}
+}
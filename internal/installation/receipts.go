@@ -0,0 +1,199 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/installation/receipt"
+	"sigs.k8s.io/krew/internal/version"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// newReceipt returns the receipt of a plugin that was installed from the
+// platform, downloaded from uri.
+func newReceipt(p environment.Paths, plugin index.Plugin, indexName string, platform index.Platform, uri string, timestamp metav1.Time) (index.Receipt, error) {
+	r := receipt.New(plugin, indexName, timestamp)
+	r.Status.URI = uri
+	r.Status.Selector = platform.Selector
+	r.Status.Digest = platformDigest(platform)
+	r.Status.KrewVersion = version.GitTag()
+	r.Status.Source.URL, r.Status.Source.Commit = indexSource(p, indexName)
+	files, err := listInstalledFiles(p.PluginVersionInstallPath(plugin.Name, plugin.Spec.Version))
+	if err != nil {
+		return r, err
+	}
+	r.Status.Files = files
+	return r, nil
+}
+
+// indexSource returns the remote URL and the checked out commit of the named
+// index, or empty strings if they can't be determined.
+func indexSource(p environment.Paths, indexName string) (url, commit string) {
+	if _, err := indexoperations.IndexBackend(p, indexName); indexName == "detached" || err != nil {
+		klog.V(2).Infof("Index %q is not configured, not recording its url and commit", indexName)
+		return "", ""
+	}
+	url, err := indexoperations.RemoteURL(p, indexName)
+	if err != nil {
+		klog.Warningf("failed to get url of index %q for the receipt: %v", indexName, err)
+	}
+	commit, err = indexoperations.Revision(p, indexName)
+	if err != nil {
+		klog.Warningf("failed to get commit of index %q for the receipt: %v", indexName, err)
+	}
+	return url, commit
+}
+
+// platformDigest returns the digest of the platform archive, preferring
+// sha256 if the platform declares multiple digests.
+func platformDigest(platform index.Platform) string {
+	if platform.Sha256 != "" {
+		return "sha256:" + platform.Sha256
+	}
+	if platform.Sha512 != "" {
+		return "sha512:" + platform.Sha512
+	}
+	return ""
+}
+
+// listInstalledFiles returns the regular files and symbolic links under dir,
+// ordered by their path.
+func listInstalledFiles(dir string) ([]index.InstalledFile, error) {
+	var files []index.InstalledFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f := index.InstalledFile{Path: filepath.ToSlash(rel)}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			if f.Link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case d.Type().IsRegular():
+			if f.Sha256, err = fileSha256(path); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unexpected file type %s of %q", d.Type(), path)
+		}
+		files = append(files, f)
+		return nil
+	})
+	return files, errors.Wrapf(err, "failed to list installed files in %q", dir)
+}
+
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReceiptsUpgraded checks if the receipts stored by older versions of krew
+// were upgraded with UpgradeReceipts.
+func ReceiptsUpgraded(p environment.Paths) (bool, error) {
+	_, err := os.Stat(p.ReceiptsUpgradedPath())
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UpgradeReceipts adds the installed platform, its digest and the list of
+// installed files to receipts stored by older versions of krew, and marks the
+// receipts as upgraded. As the files are recorded after the plugins were
+// installed, the receipts are marked to have an unverified baseline. Receipts
+// of plugins without an installation directory are left unchanged.
+func UpgradeReceipts(p environment.Paths) error {
+	receipts, err := GetInstalledPluginReceipts(p.InstallReceiptsPath())
+	if err != nil {
+		return err
+	}
+	for _, r := range receipts {
+		if len(r.Status.Files) > 0 {
+			continue
+		}
+		dir := p.PluginVersionInstallPath(r.Name, r.Spec.Version)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			klog.V(2).Infof("Not upgrading receipt of plugin %q, installation directory %q does not exist", r.Name, dir)
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to check installation directory of plugin %q", r.Name)
+		}
+
+		klog.V(1).Infof("Upgrading receipt of plugin %q", r.Name)
+		if r.Status.Files, err = listInstalledFiles(dir); err != nil {
+			return err
+		}
+		r.Status.UnverifiedBaseline = true
+		if platform, ok, err := GetMatchingPlatform(r.Spec.Platforms); err == nil && ok {
+			if r.Status.Selector == nil {
+				r.Status.Selector = platform.Selector
+			}
+			if r.Status.Digest == "" {
+				r.Status.Digest = platformDigest(platform)
+			}
+			if r.Status.URI == "" {
+				r.Status.URI = platform.URI
+			}
+		}
+		if err := receipt.Store(r, p.PluginInstallReceiptPath(r.Name)); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(os.WriteFile(p.ReceiptsUpgradedPath(), nil, 0o644), "failed to mark receipts as upgraded")
+}
+
+// receiptPlatform returns the platform of the plugin manifest in the receipt
+// that was installed. For receipts that don't record the selector of the
+// platform, it returns the platform matching the current machine.
+func receiptPlatform(r index.Receipt) (index.Platform, bool, error) {
+	if r.Status.Selector == nil {
+		return GetMatchingPlatform(r.Spec.Platforms)
+	}
+	for _, platform := range r.Spec.Platforms {
+		if reflect.DeepEqual(platform.Selector, r.Status.Selector) {
+			return platform, true, nil
+		}
+	}
+	return index.Platform{}, false, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/installation/receipt"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+const (
+	helloSha256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	worldSha256 = "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"
+)
+
+func Test_listInstalledFiles(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	tmpDir.Write("bin/foo", []byte("hello"))
+	tmpDir.Write("LICENSE", []byte("world"))
+	if err := os.MkdirAll(tmpDir.Path("empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("bin/foo", tmpDir.Path("kubectl-foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listInstalledFiles(tmpDir.Root())
+	if err != nil {
+		t.Fatalf("listInstalledFiles() failed: %v", err)
+	}
+	want := []index.InstalledFile{
+		{Path: "LICENSE", Sha256: worldSha256},
+		{Path: "bin/foo", Sha256: helloSha256},
+		{Path: "kubectl-foo", Link: "bin/foo"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("listInstalledFiles() mismatch: %s", diff)
+	}
+}
+
+func Test_newReceipt(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	p := environment.NewPaths(tmpDir.Root())
+	platform := testutil.NewPlatform().V()
+	plugin := testutil.NewPlugin().WithName("foo").WithVersion("v1.0.0").WithPlatforms(platform).V()
+	tmpDir.Write("store/foo/v1.0.0/kubectl-foo", []byte("hello"))
+
+	r, err := newReceipt(p, plugin, "detached", platform, "https://mirror.example/foo.tar.gz", metav1.Now())
+	if err != nil {
+		t.Fatalf("newReceipt() failed: %v", err)
+	}
+	want := index.ReceiptStatus{
+		Source:   index.SourceIndex{Name: "detached"},
+		URI:      "https://mirror.example/foo.tar.gz",
+		Selector: platform.Selector,
+		Digest:   "sha256:" + platform.Sha256,
+		Files:    []index.InstalledFile{{Path: "kubectl-foo", Sha256: helloSha256}},
+	}
+	want.KrewVersion = r.Status.KrewVersion
+	if diff := cmp.Diff(want, r.Status); diff != "" {
+		t.Errorf("receipt status mismatch: %s", diff)
+	}
+	if r.Status.KrewVersion == "" {
+		t.Error("expected krew version to be set")
+	}
+}
+
+func Test_platformDigest(t *testing.T) {
+	tests := []struct {
+		platform index.Platform
+		want     string
+	}{
+		{index.Platform{}, ""},
+		{index.Platform{Sha512: "cd"}, "sha512:cd"},
+		{index.Platform{Sha256: "ab", Sha512: "cd"}, "sha256:ab"},
+	}
+	for _, tt := range tests {
+		if got := platformDigest(tt.platform); got != tt.want {
+			t.Errorf("platformDigest(%+v) = %q, expected %q", tt.platform, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradeReceipts(t *testing.T) {
+	t.Setenv("KREW_OS", "linux")
+	t.Setenv("KREW_ARCH", "amd64")
+	tmpDir := testutil.NewTempDir(t)
+	p := environment.NewPaths(tmpDir.Root())
+
+	platform := testutil.NewPlatform().V()
+	old := testutil.NewReceipt().WithPlugin(testutil.NewPlugin().WithName("old").WithVersion("v1.0.0").WithPlatforms(platform).V()).V()
+	missing := testutil.NewReceipt().WithPlugin(testutil.NewPlugin().WithName("missing").WithVersion("v1.0.0").WithPlatforms(platform).V()).V()
+	tmpDir.WriteYAML("receipts/old.yaml", old)
+	tmpDir.WriteYAML("receipts/missing.yaml", missing)
+	tmpDir.Write("store/old/v1.0.0/kubectl-old", []byte("hello"))
+
+	if err := UpgradeReceipts(p); err != nil {
+		t.Fatalf("UpgradeReceipts() failed: %v", err)
+	}
+
+	got, err := receipt.Load(p.PluginInstallReceiptPath("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := old
+	want.Status.URI = platform.URI
+	want.Status.Selector = platform.Selector
+	want.Status.Digest = "sha256:" + platform.Sha256
+	want.Status.Files = []index.InstalledFile{{Path: "kubectl-old", Sha256: helloSha256}}
+	want.Status.UnverifiedBaseline = true
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("upgraded receipt mismatch: %s", diff)
+	}
+
+	got, err = receipt.Load(p.PluginInstallReceiptPath("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(missing, got); diff != "" {
+		t.Errorf("expected receipt without installation directory to be unchanged: %s", diff)
+	}
+
+	if done, err := ReceiptsUpgraded(p); err != nil || !done {
+		t.Errorf("ReceiptsUpgraded() = %v, %v; expected receipts to be marked as upgraded", done, err)
+	}
+}
+
+func TestReceiptsUpgraded(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	p := environment.NewPaths(tmpDir.Root())
+	if done, err := ReceiptsUpgraded(p); err != nil || done {
+		t.Errorf("ReceiptsUpgraded() = %v, %v; expected false without marker", done, err)
+	}
+	tmpDir.Write("receipts/.upgraded", nil)
+	if done, err := ReceiptsUpgraded(p); err != nil || !done {
+		t.Errorf("ReceiptsUpgraded() = %v, %v; expected true with marker", done, err)
+	}
+}
+
+func Test_indexSource(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	p := environment.NewPaths(tmpDir.Root())
+	tmpDir.InitEmptyGitRepo(p.IndexPath("foo"), "https://example.com/index.git")
+
+	if url, _ := indexSource(p, "foo"); url != "https://example.com/index.git" {
+		t.Errorf("indexSource() url = %q, expected the remote url", url)
+	}
+	if url, commit := indexSource(p, "missing"); url != "" || commit != "" {
+		t.Errorf("indexSource() of missing index = %q, %q, expected empty strings", url, commit)
+	}
+	if url, commit := indexSource(p, "detached"); url != "" || commit != "" {
+		t.Errorf("indexSource() of detached index = %q, %q, expected empty strings", url, commit)
+	}
+}
@@ -16,11 +16,13 @@ package installation
 
 import (
 	"os"
+	"reflect"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/krew/internal/environment"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
 	"sigs.k8s.io/krew/internal/installation/receipt"
 	"sigs.k8s.io/krew/internal/installation/semver"
 	"sigs.k8s.io/krew/pkg/constants"
@@ -51,6 +53,11 @@ func Upgrade(p environment.Paths, plugin index.Plugin, indexName string, opts In
 			plugin.Name, OSArch())
 	}
 
+	indexConfig, err := indexoperations.LoadIndexConfig(p, indexName)
+	if err != nil {
+		return err
+	}
+
 	newVersion := plugin.Spec.Version
 	newv, err := semver.Parse(newVersion)
 	if err != nil {
@@ -67,18 +74,25 @@ func Upgrade(p environment.Paths, plugin index.Plugin, indexName string, opts In
 
 	// Re-Install
 	klog.V(1).Infof("Installing new version %s", newVersion)
-	if err := install(installOperation{
+	uri, err := install(installOperation{
 		pluginName: plugin.Name,
 		platform:   candidate,
 
 		installDir: p.PluginVersionInstallPath(plugin.Name, newVersion),
 		binDir:     p.BinPath(),
-	}, opts); err != nil {
+
+		indexConfig: indexConfig,
+	}, opts)
+	if err != nil {
 		return errors.Wrap(err, "failed to install new version")
 	}
 
 	klog.V(2).Infof("Upgrading install receipt for plugin %s", plugin.Name)
-	if err = receipt.Store(receipt.New(plugin, indexName, installReceipt.CreationTimestamp), p.PluginInstallReceiptPath(plugin.Name)); err != nil {
+	r, err := newReceipt(p, plugin, indexName, candidate, uri, installReceipt.CreationTimestamp)
+	if err != nil {
+		return errors.Wrap(err, "failed to create installation receipt")
+	}
+	if err = receipt.Store(r, p.PluginInstallReceiptPath(plugin.Name)); err != nil {
 		return errors.Wrap(err, "installation receipt could not be stored, uninstall may fail")
 	}
 
@@ -101,3 +115,64 @@ func cleanupInstallation(p environment.Paths, plugin index.Plugin, oldVersion st
 	klog.V(1).Infof("Remove old plugin installation under %q", p.PluginVersionInstallPath(plugin.Name, oldVersion))
 	return os.RemoveAll(p.PluginVersionInstallPath(plugin.Name, oldVersion))
 }
+
+// Reinstall downloads and installs the plugin again, replacing the installed
+// version even if it is the same or newer. The receipt keeps the index commit
+// of the installed manifest if the manifest did not change, and the index URL
+// if the index is no longer configured.
+func Reinstall(p environment.Paths, plugin index.Plugin, indexName string, opts InstallOpts) error {
+	installReceipt, err := receipt.Load(p.PluginInstallReceiptPath(plugin.Name))
+	if os.IsNotExist(err) {
+		return ErrIsNotInstalled
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to load install receipt for plugin %q", plugin.Name)
+	}
+
+	candidate, ok, err := GetMatchingPlatform(plugin.Spec.Platforms)
+	if err != nil {
+		return errors.Wrap(err, "failed trying to find a matching platform in plugin spec")
+	}
+	if !ok {
+		return errors.Errorf("plugin %q does not offer installation for this platform (%s)",
+			plugin.Name, OSArch())
+	}
+
+	indexConfig, err := indexoperations.LoadIndexConfig(p, indexName)
+	if err != nil {
+		return err
+	}
+
+	klog.V(1).Infof("Reinstalling version %s", plugin.Spec.Version)
+	uri, err := install(installOperation{
+		pluginName: plugin.Name,
+		platform:   candidate,
+
+		installDir: p.PluginVersionInstallPath(plugin.Name, plugin.Spec.Version),
+		binDir:     p.BinPath(),
+
+		indexConfig: indexConfig,
+	}, opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to reinstall plugin")
+	}
+
+	r, err := newReceipt(p, plugin, indexName, candidate, uri, installReceipt.CreationTimestamp)
+	if err != nil {
+		return errors.Wrap(err, "failed to create installation receipt")
+	}
+	if indexName == installReceipt.Status.Source.Name && r.Status.Source.URL == "" {
+		r.Status.Source.URL = installReceipt.Status.Source.URL
+	}
+	if indexName == installReceipt.Status.Source.Name && reflect.DeepEqual(plugin.Spec, installReceipt.Spec) {
+		r.Status.Source.Commit = installReceipt.Status.Source.Commit
+	}
+	if err = receipt.Store(r, p.PluginInstallReceiptPath(plugin.Name)); err != nil {
+		return errors.Wrap(err, "installation receipt could not be stored, uninstall may fail")
+	}
+
+	if oldVersion := installReceipt.Spec.Version; oldVersion != plugin.Spec.Version {
+		klog.V(2).Infof("Starting old version cleanup")
+		return cleanupInstallation(p, plugin, oldVersion)
+	}
+	return nil
+}
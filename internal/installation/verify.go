@@ -0,0 +1,139 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/krew/internal/download"
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/pkg/constants"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+// newPlatformVerifier returns a function creating a verifier for downloads of
+// the platform, which checks every digest declared by the platform and, if the
+// platform is signed, the signature. Signature failures are fatal only if the
+// index enforces them, in which case the signature must be made with one of
+// the trusted keys of the index.
+func newPlatformVerifier(platform index.Platform, cfg indexoperations.IndexConfig) (func() (download.Verifier, error), error) {
+	newVerifier := func() (download.Verifier, error) { return newDigestVerifier(platform) }
+	enforce := cfg.SignaturePolicy == indexoperations.SignaturePolicyEnforce
+
+	if platform.Signature == nil {
+		if enforce {
+			return nil, errors.New("plugin archive is not signed, but the index requires signatures")
+		}
+		return newVerifier, nil
+	}
+	publicKey, signature, err := decodeSignature(*platform.Signature, cfg.TrustedKeys, enforce)
+	if err != nil {
+		if enforce {
+			return nil, errors.Wrap(err, "cannot verify plugin signature")
+		}
+		klog.Warningf("Cannot verify plugin signature, continuing without it: %v", err)
+		return newVerifier, nil
+	}
+
+	return func() (download.Verifier, error) {
+		digestVerifier, err := newDigestVerifier(platform)
+		if err != nil {
+			return nil, err
+		}
+		var sigVerifier download.Verifier = download.NewEd25519Verifier(publicKey, signature)
+		if !enforce {
+			sigVerifier = warningVerifier{sigVerifier}
+		}
+		return download.NewChainVerifier(digestVerifier, sigVerifier), nil
+	}, nil
+}
+
+// newDigestVerifier returns a verifier that checks all digests declared by
+// the platform.
+func newDigestVerifier(platform index.Platform) (download.Verifier, error) {
+	var verifiers []download.Verifier
+	if platform.Sha256 != "" {
+		v, err := download.NewSha256Verifier(platform.Sha256)
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, v)
+	}
+	if platform.Sha512 != "" {
+		v, err := download.NewSha512Verifier(platform.Sha512)
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, v)
+	}
+	if len(verifiers) == 0 {
+		return nil, errors.New("platform does not specify a digest of the archive")
+	}
+	return download.NewChainVerifier(verifiers...), nil
+}
+
+// decodeSignature returns the public key and the signature value of sig.
+// Named keys are looked up in trustedKeys. If requireTrusted is set, public
+// keys embedded in the manifest are only accepted if they are one of the
+// trustedKeys, since anyone who can change the manifest can also replace them.
+func decodeSignature(sig index.Signature, trustedKeys map[string]string, requireTrusted bool) (ed25519.PublicKey, []byte, error) {
+	if sig.Algorithm != "" && sig.Algorithm != constants.SignatureAlgorithmEd25519 {
+		return nil, nil, errors.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+	encodedKey := sig.PublicKey
+	if sig.KeyRef != "" {
+		var ok bool
+		if encodedKey, ok = trustedKeys[sig.KeyRef]; !ok {
+			return nil, nil, errors.Errorf("key %q is not trusted for this index", sig.KeyRef)
+		}
+	} else if requireTrusted && !isTrustedKey(encodedKey, trustedKeys) {
+		return nil, nil, errors.New("public key embedded in the manifest is not trusted for this index")
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, nil, errors.New("public key is not a base64-encoded ed25519 key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, nil, errors.New("signature is not a base64-encoded ed25519 signature")
+	}
+	return publicKey, signature, nil
+}
+
+func isTrustedKey(encodedKey string, trustedKeys map[string]string) bool {
+	for _, k := range trustedKeys {
+		if k == encodedKey {
+			return true
+		}
+	}
+	return false
+}
+
+// warningVerifier prints a warning instead of failing when the wrapped
+// verifier fails.
+type warningVerifier struct {
+	download.Verifier
+}
+
+func (v warningVerifier) Verify() error {
+	if err := v.Verifier.Verify(); err != nil {
+		klog.Warningf("Plugin signature verification failed, the index does not enforce signatures: %v", err)
+	}
+	return nil
+}
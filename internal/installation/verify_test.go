@@ -0,0 +1,204 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/krew/internal/index/indexoperations"
+	"sigs.k8s.io/krew/internal/testutil"
+	"sigs.k8s.io/krew/pkg/index"
+)
+
+func Test_newPlatformVerifier(t *testing.T) {
+	content := []byte("plugin archive")
+	sum := sha256.Sum256(content)
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	publicKey := base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	validSig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, content))
+	invalidSig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte("something else")))
+
+	enforce := indexoperations.IndexConfig{SignaturePolicy: indexoperations.SignaturePolicyEnforce}
+	enforceWithKey := indexoperations.IndexConfig{
+		SignaturePolicy: indexoperations.SignaturePolicyEnforce,
+		TrustedKeys:     map[string]string{"publisher": publicKey},
+	}
+
+	tests := []struct {
+		name          string
+		signature     *index.Signature
+		cfg           indexoperations.IndexConfig
+		wantCreateErr bool
+		wantVerifyErr bool
+	}{
+		{
+			name: "unsigned, warn",
+		},
+		{
+			name:          "unsigned, enforce",
+			cfg:           enforce,
+			wantCreateErr: true,
+		},
+		{
+			name:      "valid signature, warn",
+			signature: &index.Signature{PublicKey: publicKey, Value: validSig},
+		},
+		{
+			name:          "self-signed with embedded key, enforce",
+			signature:     &index.Signature{PublicKey: publicKey, Value: validSig},
+			cfg:           enforce,
+			wantCreateErr: true,
+		},
+		{
+			name:      "embedded key that is trusted, enforce",
+			signature: &index.Signature{PublicKey: publicKey, Value: validSig},
+			cfg:       enforceWithKey,
+		},
+		{
+			name:      "invalid signature, warn",
+			signature: &index.Signature{PublicKey: publicKey, Value: invalidSig},
+		},
+		{
+			name:          "invalid signature, enforce",
+			signature:     &index.Signature{PublicKey: publicKey, Value: invalidSig},
+			cfg:           enforceWithKey,
+			wantVerifyErr: true,
+		},
+		{
+			name:      "trusted key reference",
+			signature: &index.Signature{KeyRef: "publisher", Value: validSig},
+			cfg:       enforceWithKey,
+		},
+		{
+			name:          "untrusted key reference, enforce",
+			signature:     &index.Signature{KeyRef: "someone", Value: validSig},
+			cfg:           enforceWithKey,
+			wantCreateErr: true,
+		},
+		{
+			name:      "untrusted key reference, warn",
+			signature: &index.Signature{KeyRef: "someone", Value: validSig},
+		},
+		{
+			name:          "unsupported algorithm, enforce",
+			signature:     &index.Signature{Algorithm: "rsa", PublicKey: publicKey, Value: validSig},
+			cfg:           enforce,
+			wantCreateErr: true,
+		},
+		{
+			name:          "malformed signature, enforce",
+			signature:     &index.Signature{PublicKey: publicKey, Value: "not-base64!"},
+			cfg:           enforce,
+			wantCreateErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platform := testutil.NewPlatform().WithSHA256(hex.EncodeToString(sum[:])).WithSignature(tt.signature).V()
+			newVerifier, err := newPlatformVerifier(platform, tt.cfg)
+			if (err != nil) != tt.wantCreateErr {
+				t.Fatalf("newPlatformVerifier() error = %v, wantErr %v", err, tt.wantCreateErr)
+			}
+			if err != nil {
+				return
+			}
+			v, err := newVerifier()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Write(content); err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Verify(); (err != nil) != tt.wantVerifyErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantVerifyErr)
+			}
+		})
+	}
+}
+
+func Test_newPlatformVerifier_checksSha256(t *testing.T) {
+	content := []byte("plugin archive")
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	platform := testutil.NewPlatform().WithSignature(&index.Signature{
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(key, content)),
+	}).V()
+
+	newVerifier, err := newPlatformVerifier(platform, indexoperations.IndexConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := newVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Verify(); err == nil {
+		t.Fatal("expected sha256 mismatch even though the signature is valid")
+	}
+}
+
+func Test_newDigestVerifier(t *testing.T) {
+	content := []byte("plugin archive")
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+	goodSha256, goodSha512 := hex.EncodeToString(sum256[:]), hex.EncodeToString(sum512[:])
+	wrongSha256 := strings.Repeat("0", 64)
+	wrongSha512 := strings.Repeat("0", 128)
+
+	tests := []struct {
+		name          string
+		sha256        string
+		sha512        string
+		wantCreateErr bool
+		wantVerifyErr bool
+	}{
+		{name: "sha256 only", sha256: goodSha256},
+		{name: "sha512 only", sha512: goodSha512},
+		{name: "both digests", sha256: goodSha256, sha512: goodSha512},
+		{name: "wrong sha512", sha256: goodSha256, sha512: wrongSha512, wantVerifyErr: true},
+		{name: "wrong sha256", sha256: wrongSha256, sha512: goodSha512, wantVerifyErr: true},
+		{name: "no digest", wantCreateErr: true},
+		{name: "malformed sha256", sha256: "abc", wantCreateErr: true},
+		{name: "malformed sha512", sha256: goodSha256, sha512: goodSha256, wantCreateErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platform := testutil.NewPlatform().WithSHA256(tt.sha256).WithSHA512(tt.sha512).V()
+			v, err := newDigestVerifier(platform)
+			if (err != nil) != tt.wantCreateErr {
+				t.Fatalf("newDigestVerifier() error = %v, wantErr %v", err, tt.wantCreateErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := v.Write(content); err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Verify(); (err != nil) != tt.wantVerifyErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantVerifyErr)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@
 package pathutil
 
 import (
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -30,7 +32,7 @@ func IsSubPath(basePath, subPath string) (string, bool) {
 	if err != nil {
 		return "", false
 	}
-	if strings.HasPrefix(extendingPath, "..") {
+	if extendingPath == ".." || strings.HasPrefix(extendingPath, ".."+string(filepath.Separator)) {
 		return "", false
 	}
 	return extendingPath, true
@@ -45,6 +47,65 @@ func ReplaceBase(path, old, replacement string) (string, error) {
 	return filepath.Join(replacement, extendingPath), nil
 }
 
+// ResolveLink returns the location the symbolic link at path points to, with
+// all symbolic links along the way evaluated. Trailing elements of the target
+// that do not exist (yet) are appended to the resolved prefix, so dangling
+// links are resolved to where they would point once the target is created.
+func ResolveLink(path string) (string, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read link %q", path)
+	}
+	if !filepath.IsAbs(target) && filepath.VolumeName(target) == "" {
+		// do not clean the joined path, ".." must be applied after
+		// evaluating the symbolic links that precede it
+		target = filepath.Dir(path) + string(filepath.Separator) + target
+	}
+
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(target)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", errors.Wrapf(err, "failed to evaluate link %q", path)
+		}
+		i := strings.LastIndexAny(target, `/`+string(filepath.Separator))
+		if i <= len(filepath.VolumeName(target)) {
+			return "", errors.Wrapf(err, "failed to evaluate link %q", path)
+		}
+		rest = append([]string{target[i+1:]}, rest...)
+		target = target[:i]
+	}
+}
+
+// CheckSymlinks walks root and returns an error if any symbolic link in it
+// resolves to a location outside of root.
+func CheckSymlinks(root string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return errors.Wrapf(err, "failed to evaluate %q", root)
+	}
+	return filepath.WalkDir(realRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		resolved, err := ResolveLink(path)
+		if err != nil {
+			return err
+		}
+		if _, ok := IsSubPath(realRoot, resolved); !ok {
+			rel, _ := filepath.Rel(realRoot, path)
+			return errors.Errorf("symbolic link %q resolves to %q, which is outside of %q", rel, resolved, root)
+		}
+		return nil
+	})
+}
+
 // CanonicalPluginName resolves a plugin's index and name from input string.
 // If an index is not specified, the default index name is assumed.
 func CanonicalPluginName(in string) (string, string) {
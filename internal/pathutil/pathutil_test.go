@@ -15,8 +15,10 @@
 package pathutil
 
 import (
+	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"testing"
 )
 
@@ -63,6 +65,22 @@ func TestIsSubPathExtending(t *testing.T) {
 				extendingPath: filepath.FromSlash("a"),
 			},
 		},
+		{
+			name: "is extending with dots in name",
+			args: args{
+				basePath:      filepath.FromSlash("a/b"),
+				extendingPath: filepath.FromSlash("a/b/..c"),
+			},
+			wantExtending:   "..c",
+			wantIsExtending: true,
+		},
+		{
+			name: "is not extending sibling with dots in name",
+			args: args{
+				basePath:      filepath.FromSlash("a/b"),
+				extendingPath: filepath.FromSlash("a/..b"),
+			},
+		},
 		{
 			name: "base path is not clean",
 			args: args{
@@ -208,3 +226,68 @@ func TestCanonicalPluginName(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires extra privileges on windows")
+	}
+	tests := []struct {
+		name    string
+		links   map[string]string // link path -> target, in slash form
+		wantErr bool
+	}{
+		{
+			name:  "no links",
+			links: map[string]string{},
+		},
+		{
+			name:  "link to sibling",
+			links: map[string]string{"root/bin/tool": "../libexec/tool"},
+		},
+		{
+			name:  "dangling link within root",
+			links: map[string]string{"root/tool": "missing/../tool2"},
+		},
+		{
+			name:    "link to parent",
+			links:   map[string]string{"root/tool": "../outside"},
+			wantErr: true,
+		},
+		{
+			name:    "dangling link outside of root",
+			links:   map[string]string{"root/tool": "../missing"},
+			wantErr: true,
+		},
+		{
+			name: "link escaping through another link",
+			links: map[string]string{
+				"root/a":    ".",
+				"root/tool": "a/../outside",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			for _, dir := range []string{"root/bin", "root/libexec"} {
+				if err := os.MkdirAll(filepath.Join(tmp, filepath.FromSlash(dir)), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(filepath.Join(tmp, "outside"), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for link, target := range tt.links {
+				if err := os.Symlink(filepath.FromSlash(target), filepath.Join(tmp, filepath.FromSlash(link))); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CheckSymlinks(filepath.Join(tmp, "root"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSymlinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
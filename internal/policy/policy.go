@@ -0,0 +1,350 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy implements organization policies that restrict which plugins
+// and indexes can be used with krew.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/krew/internal/installation/semver"
+)
+
+// EnvPolicyFile is the environment variable that points to a policy file
+// which is enforced in addition to the system policy file.
+const EnvPolicyFile = "KREW_POLICY"
+
+// Policy holds the rules of a single policy file.
+type Policy struct {
+	// Plugins restricts which plugins can be installed. Patterns match the
+	// plugin name, or INDEX/NAME if they contain a slash, and "*" matches any
+	// sequence of characters.
+	Plugins Rules `json:"plugins,omitempty"`
+
+	// Indexes restricts the URLs of indexes that can be added and that
+	// plugins can be installed from. "*" matches any sequence of characters.
+	Indexes Rules `json:"indexes,omitempty"`
+
+	// RequiredIndexes are indexes that must be configured and can't be
+	// removed.
+	RequiredIndexes []RequiredIndex `json:"requiredIndexes,omitempty"`
+
+	// MinVersions maps plugin patterns to the lowest version that can be
+	// installed, such as "v1.2.0".
+	MinVersions map[string]string `json:"minVersions,omitempty"`
+
+	// DenyDevelopmentFlags forbids installing plugins with the
+	// development-only --manifest, --manifest-url and --archive flags.
+	DenyDevelopmentFlags bool `json:"denyDevelopmentFlags,omitempty"`
+
+	source string
+}
+
+// Rules lists allowed and denied patterns. Denied patterns take precedence.
+// If there are allowed patterns, anything not matching one of them is denied.
+type Rules struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// RequiredIndex is an index that must be configured with the given URL.
+type RequiredIndex struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Validate checks that the policy has valid values.
+func (p Policy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Plugins.Allow...), p.Plugins.Deny...) {
+		if pattern == "" || strings.Count(pattern, "/") > 1 {
+			return errors.Errorf("invalid plugin pattern %q", pattern)
+		}
+	}
+	for _, pattern := range append(append([]string{}, p.Indexes.Allow...), p.Indexes.Deny...) {
+		if pattern == "" {
+			return errors.New("index patterns can't be empty")
+		}
+	}
+	for _, r := range p.RequiredIndexes {
+		if r.Name == "" || r.URL == "" {
+			return errors.Errorf("required index %+v must have a name and url", r)
+		}
+	}
+	for pattern, v := range p.MinVersions {
+		if pattern == "" || strings.Count(pattern, "/") > 1 {
+			return errors.Errorf("invalid plugin pattern %q", pattern)
+		}
+		if _, err := semver.Parse(v); err != nil {
+			return errors.Wrapf(err, "invalid minimum version for %q", pattern)
+		}
+	}
+	return nil
+}
+
+// Decision is the outcome of checking an operation against the policies.
+type Decision struct {
+	Allowed bool
+	// Reason explains why the operation was allowed or denied.
+	Reason string
+	// Source is the policy file that made the decision. It is empty if no
+	// policy applies.
+	Source string
+}
+
+// Err returns an error describing the decision if the operation is denied.
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	return errors.Errorf("denied by policy %s: %s", d.Source, d.Reason)
+}
+
+// Set is the list of policies in effect. An operation must be allowed by all
+// of them.
+type Set struct {
+	policies []Policy
+}
+
+// SystemPath returns the location of the system-wide policy file.
+func SystemPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "krew", "policy.yaml")
+	}
+	return "/etc/krew/policy.yaml"
+}
+
+// Load reads the system policy file, if it exists, and the file that
+// KREW_POLICY points to, if set.
+func Load() (Set, error) {
+	var s Set
+	p, err := readPolicy(SystemPath())
+	if err == nil {
+		s.policies = append(s.policies, p)
+	} else if !os.IsNotExist(errors.Cause(err)) {
+		return s, err
+	}
+	if path := os.Getenv(EnvPolicyFile); path != "" {
+		klog.V(4).Infof("using policy file from %s=%s", EnvPolicyFile, path)
+		p, err := readPolicy(path)
+		if err != nil {
+			return s, err
+		}
+		s.policies = append(s.policies, p)
+	}
+	return s, nil
+}
+
+// LoadFiles reads the given policy files. All of them must exist.
+func LoadFiles(paths ...string) (Set, error) {
+	var s Set
+	for _, path := range paths {
+		p, err := readPolicy(path)
+		if err != nil {
+			return s, err
+		}
+		s.policies = append(s.policies, p)
+	}
+	return s, nil
+}
+
+func readPolicy(path string) (Policy, error) {
+	var p Policy
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to read policy file %q", path)
+	}
+	if err := yaml.UnmarshalStrict(b, &p); err != nil {
+		return p, errors.Wrapf(err, "failed to parse policy file %q", path)
+	}
+	if err := p.Validate(); err != nil {
+		return p, errors.Wrapf(err, "invalid policy file %q", path)
+	}
+	p.source = path
+	return p, nil
+}
+
+// Sources returns the files the policies were loaded from.
+func (s Set) Sources() []string {
+	var out []string
+	for _, p := range s.policies {
+		out = append(out, p.source)
+	}
+	return out
+}
+
+// RequiredIndexes returns the indexes required by any of the policies.
+func (s Set) RequiredIndexes() []RequiredIndex {
+	var out []RequiredIndex
+	for _, p := range s.policies {
+		out = append(out, p.RequiredIndexes...)
+	}
+	return out
+}
+
+// CheckPlugin decides if the given version of a plugin can be installed from
+// the named index.
+func (s Set) CheckPlugin(indexName, name, version string) Decision {
+	var allowedBy []string
+	for _, p := range s.policies {
+		d := p.checkPlugin(indexName, name, version)
+		if !d.Allowed {
+			return d
+		}
+		if d.Reason != "" {
+			allowedBy = append(allowedBy, d.Reason)
+		}
+	}
+	return allowed(allowedBy)
+}
+
+func (p Policy) checkPlugin(indexName, name, version string) Decision {
+	if pattern, ok := matchPlugin(p.Plugins.Deny, indexName, name); ok {
+		return p.deny("plugin %q matches denied pattern %q", name, pattern)
+	}
+	var reasons []string
+	if len(p.Plugins.Allow) > 0 {
+		pattern, ok := matchPlugin(p.Plugins.Allow, indexName, name)
+		if !ok {
+			return p.deny("plugin %q is not in the list of allowed plugins", name)
+		}
+		reasons = append(reasons, fmt.Sprintf("plugin %q matches allowed pattern %q", name, pattern))
+	}
+	var patterns []string
+	for pattern := range p.MinVersions {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		minVersion := p.MinVersions[pattern]
+		if _, ok := matchPlugin([]string{pattern}, indexName, name); !ok {
+			continue
+		}
+		v, err := semver.Parse(version)
+		if err != nil {
+			return p.deny("plugin %q has invalid version %q: %v", name, version, err)
+		}
+		minV, _ := semver.Parse(minVersion)
+		if semver.Less(v, minV) {
+			return p.deny("plugin %q version %s is lower than the minimum version %s", name, version, minVersion)
+		}
+		reasons = append(reasons, fmt.Sprintf("version %s is at least %s", version, minVersion))
+	}
+	return Decision{Allowed: true, Reason: strings.Join(reasons, ", "), Source: p.source}
+}
+
+// CheckIndex decides if an index with the given name and URL can be added or
+// used to install plugins.
+func (s Set) CheckIndex(name, url string) Decision {
+	var allowedBy []string
+	for _, p := range s.policies {
+		for _, r := range p.RequiredIndexes {
+			if r.Name == name && r.URL != url {
+				return p.deny("index %q is required to have url %q", name, r.URL)
+			}
+		}
+		if pattern, ok := match(p.Indexes.Deny, url); ok {
+			return p.deny("index url %q matches denied pattern %q", url, pattern)
+		}
+		if len(p.Indexes.Allow) > 0 {
+			pattern, ok := match(p.Indexes.Allow, url)
+			if !ok {
+				return p.deny("index url %q is not in the list of allowed indexes", url)
+			}
+			allowedBy = append(allowedBy, fmt.Sprintf("index url %q matches allowed pattern %q", url, pattern))
+		}
+	}
+	return allowed(allowedBy)
+}
+
+// CheckIndexRemoval decides if the named index can be removed.
+func (s Set) CheckIndexRemoval(name string) Decision {
+	for _, p := range s.policies {
+		for _, r := range p.RequiredIndexes {
+			if r.Name == name {
+				return p.deny("index %q is required", name)
+			}
+		}
+	}
+	return allowed(nil)
+}
+
+// CheckDevelopmentFlags decides if plugins can be installed with the given
+// development-only flags, such as "--manifest".
+func (s Set) CheckDevelopmentFlags(flags ...string) Decision {
+	if len(flags) == 0 {
+		return allowed(nil)
+	}
+	for _, p := range s.policies {
+		if p.DenyDevelopmentFlags {
+			return p.deny("development flags are not allowed: %s", strings.Join(flags, ", "))
+		}
+	}
+	return allowed(nil)
+}
+
+func (p Policy) deny(format string, args ...interface{}) Decision {
+	return Decision{Reason: fmt.Sprintf(format, args...), Source: p.source}
+}
+
+func allowed(reasons []string) Decision {
+	if len(reasons) == 0 {
+		return Decision{Allowed: true, Reason: "no policy rules apply"}
+	}
+	return Decision{Allowed: true, Reason: strings.Join(reasons, "; ")}
+}
+
+// matchPlugin returns the first pattern that matches the plugin name, or
+// INDEX/NAME for patterns with a slash.
+func matchPlugin(patterns []string, indexName, name string) (string, bool) {
+	for _, pattern := range patterns {
+		s := name
+		if strings.Contains(pattern, "/") {
+			s = indexName + "/" + name
+		}
+		if globMatch(pattern, s) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func match(patterns []string, s string) (string, bool) {
+	for _, pattern := range patterns {
+		if globMatch(pattern, s) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// globMatch reports whether s matches pattern, where "*" matches any
+// sequence of characters, including "/".
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(s)
+}
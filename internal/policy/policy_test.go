@@ -0,0 +1,171 @@
+// Copyright 2026 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+
+	"sigs.k8s.io/krew/internal/testutil"
+)
+
+const testPolicy = `
+plugins:
+  allow: ["ctx", "ns", "corp/*", "view-*"]
+  deny: ["view-secret"]
+indexes:
+  allow: ["https://github.com/kubernetes-sigs/*", "https://git.corp.example/*"]
+requiredIndexes:
+- name: corp
+  url: https://git.corp.example/krew-index.git
+minVersions:
+  ns: v0.9.0
+denyDevelopmentFlags: true
+`
+
+func loadTestPolicy(t *testing.T, content string) Set {
+	t.Helper()
+	tmpDir := testutil.NewTempDir(t)
+	tmpDir.Write("policy.yaml", []byte(content))
+	s, err := LoadFiles(tmpDir.Path("policy.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFiles() failed: %v", err)
+	}
+	return s
+}
+
+func TestSet_CheckPlugin(t *testing.T) {
+	s := loadTestPolicy(t, testPolicy)
+	tests := []struct {
+		index, name, version string
+		allowed              bool
+	}{
+		{"default", "ctx", "v1.0.0", true},
+		{"default", "view-utilization", "v1.0.0", true},
+		{"default", "view-secret", "v1.0.0", false},
+		{"default", "foo", "v1.0.0", false},
+		{"corp", "foo", "v1.0.0", true},
+		{"default", "ns", "v0.9.0", true},
+		{"default", "ns", "v0.8.9", false},
+		{"default", "ns", "0.9.0", false},
+	}
+	for _, tt := range tests {
+		d := s.CheckPlugin(tt.index, tt.name, tt.version)
+		if d.Allowed != tt.allowed {
+			t.Errorf("CheckPlugin(%q, %q, %q) = %+v, expected allowed=%v", tt.index, tt.name, tt.version, d, tt.allowed)
+		}
+		if (d.Err() == nil) != tt.allowed {
+			t.Errorf("CheckPlugin(%q, %q, %q).Err() = %v", tt.index, tt.name, tt.version, d.Err())
+		}
+	}
+}
+
+func TestSet_CheckIndex(t *testing.T) {
+	s := loadTestPolicy(t, testPolicy)
+	tests := []struct {
+		name, url string
+		allowed   bool
+	}{
+		{"default", "https://github.com/kubernetes-sigs/krew-index.git", true},
+		{"corp", "https://git.corp.example/krew-index.git", true},
+		{"corp", "https://git.corp.example/other-index.git", false},
+		{"other", "https://github.com/someone/krew-index.git", false},
+	}
+	for _, tt := range tests {
+		if d := s.CheckIndex(tt.name, tt.url); d.Allowed != tt.allowed {
+			t.Errorf("CheckIndex(%q, %q) = %+v, expected allowed=%v", tt.name, tt.url, d, tt.allowed)
+		}
+	}
+
+	if d := s.CheckIndexRemoval("corp"); d.Allowed {
+		t.Error("expected removal of required index to be denied")
+	}
+	if d := s.CheckIndexRemoval("default"); !d.Allowed {
+		t.Errorf("expected removal of index to be allowed, got %+v", d)
+	}
+}
+
+func TestSet_CheckDevelopmentFlags(t *testing.T) {
+	s := loadTestPolicy(t, testPolicy)
+	if d := s.CheckDevelopmentFlags(); !d.Allowed {
+		t.Errorf("expected no flags to be allowed, got %+v", d)
+	}
+	if d := s.CheckDevelopmentFlags("--manifest"); d.Allowed {
+		t.Error("expected --manifest to be denied")
+	}
+	if d := (Set{}).CheckDevelopmentFlags("--manifest"); !d.Allowed {
+		t.Errorf("expected --manifest to be allowed without policy, got %+v", d)
+	}
+}
+
+func TestSet_MultiplePolicies(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	tmpDir.Write("system.yaml", []byte(`plugins: {deny: ["foo"]}`))
+	tmpDir.Write("user.yaml", []byte(`plugins: {deny: ["bar"]}`))
+	s, err := LoadFiles(tmpDir.Path("system.yaml"), tmpDir.Path("user.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		if d := s.CheckPlugin("default", name, "v1.0.0"); d.Allowed {
+			t.Errorf("expected %q to be denied", name)
+		}
+	}
+	if d := s.CheckPlugin("default", "bar", "v1.0.0"); d.Source != tmpDir.Path("user.yaml") {
+		t.Errorf("expected denial from user policy, got source %q", d.Source)
+	}
+	if d := s.CheckPlugin("default", "baz", "v1.0.0"); !d.Allowed {
+		t.Errorf("expected baz to be allowed, got %+v", d)
+	}
+}
+
+func TestLoadFiles_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":   `pluginz: {}`,
+		"bad version":     `minVersions: {foo: "1.0"}`,
+		"empty pattern":   `plugins: {allow: [""]}`,
+		"bad pattern":     `plugins: {deny: ["a/b/c"]}`,
+		"required no url": `requiredIndexes: [{name: foo}]`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := testutil.NewTempDir(t)
+			tmpDir.Write("policy.yaml", []byte(content))
+			if _, err := LoadFiles(tmpDir.Path("policy.yaml")); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if _, err := LoadFiles("/nonexistent/policy.yaml"); err == nil {
+		t.Error("expected error for missing policy file")
+	}
+}
+
+func TestLoad_Env(t *testing.T) {
+	tmpDir := testutil.NewTempDir(t)
+	tmpDir.Write("policy.yaml", []byte(`plugins: {deny: ["foo"]}`))
+	t.Setenv(EnvPolicyFile, tmpDir.Path("policy.yaml"))
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if d := s.CheckPlugin("default", "foo", "v1.0.0"); d.Allowed {
+		t.Error("expected foo to be denied by KREW_POLICY")
+	}
+
+	t.Setenv(EnvPolicyFile, tmpDir.Path("missing.yaml"))
+	if _, err := Load(); err == nil {
+		t.Error("expected error for missing KREW_POLICY file")
+	}
+}
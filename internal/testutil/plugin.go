@@ -101,4 +101,8 @@ func (p *R) WithFiles(v []index.FileOperation) *R    { p.v.Files = v; return p }
 func (p *R) WithBin(v string) *R                     { p.v.Bin = v; return p }
 func (p *R) WithURI(v string) *R                     { p.v.URI = v; return p }
 func (p *R) WithSHA256(v string) *R                  { p.v.Sha256 = v; return p }
+func (p *R) WithSHA512(v string) *R                  { p.v.Sha512 = v; return p }
+func (p *R) WithMirrors(v ...string) *R              { p.v.Mirrors = v; return p }
+func (p *R) WithFormat(v string) *R                  { p.v.Format = v; return p }
+func (p *R) WithSignature(v *index.Signature) *R     { p.v.Signature = v; return p }
 func (p *R) V() index.Platform                       { return p.v }
@@ -24,4 +24,15 @@ const (
 	DefaultIndexURI = "https://github.com/kubernetes-sigs/krew-index.git"
 	// DefaultIndexName is a magic string that's used for a plugin name specified without an index.
 	DefaultIndexName = "default"
+	// RetiredPluginsFile lists the plugins that were removed from, renamed in
+	// or deprecated by an index, at the root of the index.
+	RetiredPluginsFile = "retired.yaml"
+
+	// BinaryFormat is the platform format of downloads that are not archives,
+	// but the plugin executable itself.
+	BinaryFormat = "binary"
+
+	// SignatureAlgorithmEd25519 is the only supported plugin signature
+	// algorithm, and the default when none is specified.
+	SignatureAlgorithmEd25519 = "ed25519"
 )
@@ -42,16 +42,50 @@ type PluginSpec struct {
 type Platform struct {
 	URI    string `json:"uri,omitempty"`
 	Sha256 string `json:"sha256,omitempty"`
+	// Sha512 can be specified in addition to or instead of Sha256. Every
+	// specified digest must match the downloaded archive.
+	Sha512 string `json:"sha512,omitempty"`
+
+	// Mirrors lists alternative locations of the same archive. They are tried
+	// in order when downloading from URI fails, and must match the digests as well.
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// Signature is an optional detached signature of the archive by the
+	// plugin publisher.
+	Signature *Signature `json:"signature,omitempty"`
 
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 	Files    []FileOperation       `json:"files"`
 
+	// Format specifies how the downloaded file is unpacked. If unset, the
+	// archive format is detected from the file contents. The "binary" format
+	// is for downloads that are a bare executable, which is saved at Bin.
+	Format string `json:"format,omitempty"`
+
 	// Bin specifies the path to the plugin executable.
 	// The path is relative to the root of the installation folder.
 	// The binary will be linked after all FileOperations are executed.
 	Bin string `json:"bin"`
 }
 
+// Signature is a detached signature of the downloaded archive. Exactly one of
+// PublicKey or KeyRef identifies the key the signature is verified with.
+type Signature struct {
+	// Algorithm is the signature algorithm. Only "ed25519" is supported and it
+	// is assumed when unset.
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// PublicKey is the base64-encoded public key of the publisher.
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// KeyRef is the name of a publisher key that is trusted in the local
+	// configuration of the index.
+	KeyRef string `json:"keyRef,omitempty"`
+
+	// Value is the base64-encoded signature of the archive.
+	Value string `json:"value"`
+}
+
 // FileOperation specifies a file copying operation from plugin archive to the
 // installation directory.
 type FileOperation struct {
@@ -69,10 +103,51 @@ type Receipt struct {
 // ReceiptStatus contains information about the installed plugin.
 type ReceiptStatus struct {
 	Source SourceIndex `json:"source"`
+
+	// URI is the location the installed archive was downloaded from. It is
+	// either the platform URI or one of its mirrors.
+	URI string `json:"uri,omitempty"`
+
+	// Selector is the selector of the platform that was installed.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Digest is the verified digest of the installed archive, such as
+	// "sha256:<hex>".
+	Digest string `json:"digest,omitempty"`
+
+	// Files lists the contents of the installation directory of the plugin.
+	Files []InstalledFile `json:"files,omitempty"`
+
+	// UnverifiedBaseline is set if Files were not recorded when the plugin
+	// was installed, but later when the receipt of an older version of krew
+	// was upgraded. The files may have been modified before.
+	UnverifiedBaseline bool `json:"unverifiedBaseline,omitempty"`
+
+	// KrewVersion is the version of krew that installed the plugin.
+	KrewVersion string `json:"krewVersion,omitempty"`
 }
 
 // SourceIndex contains information about the index a plugin was installed from.
 type SourceIndex struct {
 	// Name is the configured name of an index a plugin was installed from.
 	Name string `json:"name"`
+
+	// URL is the remote URL of the index.
+	URL string `json:"url,omitempty"`
+
+	// Commit is the commit of the index the plugin manifest was read from.
+	Commit string `json:"commit,omitempty"`
+}
+
+// InstalledFile describes a file or symbolic link in the installation
+// directory of a plugin.
+type InstalledFile struct {
+	// Path is the slash-separated path relative to the installation directory.
+	Path string `json:"path"`
+
+	// Sha256 is the hex-encoded digest of a regular file.
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Link is the target of a symbolic link.
+	Link string `json:"link,omitempty"`
 }